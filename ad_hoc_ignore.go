@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// adHocIgnoresEnvVar holds ad hoc ignore rules, either in json or as url-pattern=error-pattern lines.
+const adHocIgnoresEnvVar = "MUFFET_FILTER_IGNORES"
+
+const ignoreFlag = "--ignore"
+
+// parseAdHocRule parses the url-pattern=error-pattern shorthand. The error pattern follows the first "=", so a "=" in
+// the url pattern, e.g. in a query, is written as "\=", which also matches "=" in a regular expression. Without "=",
+// the rule ignores any error of the url.
+func parseAdHocRule(source, text string) (rule IgnoreRule, err error) {
+	rule.source, rule.adHoc = source, true
+	rule.Url = text
+	if i := unescapedEquals.FindStringIndex(text); i != nil {
+		rule.Url, rule.Error = text[:i[1]-1], text[i[1]:]
+	}
+	// the url also matches by equality, so without the escapes
+	rule.Url = strings.ReplaceAll(rule.Url, `\=`, "=")
+	if rule.Url == "" {
+		return rule, fmt.Errorf("invalid ignore rule from %s: %s, expected url-pattern=error-pattern", source, text)
+	}
+	for _, pattern := range []string{rule.Url, rule.Error} {
+		if _, err = regexp.Compile(pattern); err != nil {
+			return rule, fmt.Errorf("invalid ignore rule from %s: %s, invalid pattern: %s: %w", source, text, pattern, err)
+		}
+	}
+	return
+}
+
+// unescapedEquals matches up to the first "=", which is not escaped as "\=".
+var unescapedEquals = regexp.MustCompile(`^(?:[^\\=]|\\.)*=`)
+
+// parseAdHocRules parses rules in json, a list of rules like in an ignores file, or one shorthand rule per line.
+func parseAdHocRules(source, text string) (rules []IgnoreRule, err error) {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		doc, err := parseIgnoreDocument(source, []byte(trimmed))
+		if err != nil {
+			return nil, err
+		}
+		file, err := decodeIgnoreFile(source, doc)
+		if err != nil {
+			return nil, err
+		} else if len(file.Include) > 0 {
+			return nil, fmt.Errorf("%s: include is only supported in ignores files", source)
+		} else if len(file.Use) > 0 {
+			return nil, fmt.Errorf("%s: rule packs are only supported in ignores files", source)
+		} else if len(file.Definitions) > 0 {
+			return nil, fmt.Errorf("%s: definitions are only supported in ignores files", source)
+		} else if len(file.Expectations) > 0 {
+			return nil, fmt.Errorf("%s: expectations are only supported in ignores files", source)
+		}
+		for i, rule := range file.Rules {
+			rule.source, rule.index, rule.adHoc = source, i, true
+			rules = append(rules, rule)
+		}
+		return rules, nil
+	}
+
+	for _, line := range strings.Split(trimmed, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		rule, err := parseAdHocRule(source, line)
+		if err != nil {
+			return nil, err
+		}
+		rule.index = len(rules)
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// loadAdHocRules returns the rules given via --ignore and $MUFFET_FILTER_IGNORES, e.g. for a one-off CI job.
+func loadAdHocRules(args *arguments) (rules []IgnoreRule, err error) {
+	for i, text := range args.Ignore {
+		var rule IgnoreRule
+		if rule, err = parseAdHocRule(ignoreFlag, text); err != nil {
+			return
+		}
+		rule.index = i
+		rules = append(rules, rule)
+	}
+	if text := os.Getenv(adHocIgnoresEnvVar); text != "" {
+		var envRules []IgnoreRule
+		if envRules, err = parseAdHocRules("$"+adHocIgnoresEnvVar, text); err != nil {
+			return
+		}
+		rules = append(rules, envRules...)
+	}
+
+	if args.Verbose {
+		for _, rule := range rules {
+			fmt.Printf("%s: url: %s, error: %s\n", rule.origin(), rule.Url, rule.Error)
+		}
+	}
+	return
+}
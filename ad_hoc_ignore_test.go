@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdHocRule(t *testing.T) {
+	rule, err := parseAdHocRule(ignoreFlag, "https://partner.com/.*=5..")
+	assert.Nil(t, err)
+	assert.Equal(t, IgnoreRule{Url: "https://partner.com/.*", Error: "5..", source: ignoreFlag, adHoc: true}, rule)
+
+	// the error pattern follows the first "=", a "=" in the url is escaped
+	rule, err = parseAdHocRule(ignoreFlag, `https://partner.com/?a\=b&c\=d=timeout=1`)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://partner.com/?a=b&c=d", rule.Url)
+	assert.Equal(t, "timeout=1", rule.Error)
+	assert.True(t, rule.isMatch("https://my-site.com/", UrlErrorLink{"https://partner.com/?a=b&c=d", "timeout=1"}))
+
+	rule, err = parseAdHocRule(ignoreFlag, `^https://partner\.com/\?a\=b$`)
+	assert.Nil(t, err)
+	assert.Equal(t, `^https://partner\.com/\?a=b$`, rule.Url)
+	assert.Equal(t, "", rule.Error)
+	assert.True(t, isPatternMatch(rule.Url, "https://partner.com/?a=b"))
+
+	rule, err = parseAdHocRule(ignoreFlag, "https://partner.com/")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://partner.com/", rule.Url)
+	assert.Equal(t, "", rule.Error)
+
+	_, err = parseAdHocRule(ignoreFlag, "=404")
+	assert.EqualError(t, err, "invalid ignore rule from --ignore: =404, expected url-pattern=error-pattern")
+
+	_, err = parseAdHocRule(ignoreFlag, "https://partner.com/(=404")
+	assert.EqualError(t, err, "invalid ignore rule from --ignore: https://partner.com/(=404, invalid pattern: https://partner.com/(: error parsing regexp: missing closing ): `https://partner.com/(`")
+
+	_, err = parseAdHocRule(ignoreFlag, "https://partner.com/=[5")
+	assert.EqualError(t, err, "invalid ignore rule from --ignore: https://partner.com/=[5, invalid pattern: [5: error parsing regexp: missing closing ]: `[5`")
+}
+
+func TestParseAdHocRules(t *testing.T) {
+	rules, err := parseAdHocRules("$MUFFET_FILTER_IGNORES", `
+https://a.com/=404
+https://b.com/=timeout
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, []IgnoreRule{
+		{Url: "https://a.com/", Error: "404", source: "$MUFFET_FILTER_IGNORES", adHoc: true},
+		{Url: "https://b.com/", Error: "timeout", source: "$MUFFET_FILTER_IGNORES", index: 1, adHoc: true},
+	}, rules)
+
+	rules, err = parseAdHocRules("$MUFFET_FILTER_IGNORES", `[{"url": "https://a.com/", "error": "404", "reason": "partner down"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, []IgnoreRule{
+		{Url: "https://a.com/", Error: "404", Reason: "partner down", source: "$MUFFET_FILTER_IGNORES", adHoc: true},
+	}, rules)
+
+	_, err = parseAdHocRules("$MUFFET_FILTER_IGNORES", `{"include": ["other.json"]}`)
+	assert.EqualError(t, err, "$MUFFET_FILTER_IGNORES: include is only supported in ignores files")
+
+	_, err = parseAdHocRules("$MUFFET_FILTER_IGNORES", `[{"url": }]`)
+	assert.ErrorContains(t, err, "$MUFFET_FILTER_IGNORES:1:")
+}
+
+func TestLoadIgnoreListAdHocRules(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "https://b.com/=timeout")
+
+	rules, err := loadIgnoreList(&arguments{
+		IgnoresJson: []string{"testdata/urlErrorIgnore.json"},
+		Ignore:      []string{"https://a.com/=404"},
+	})
+	assert.Nil(t, err)
+	// the ad hoc rules come first
+	assert.Equal(t, "https://a.com/", rules[0].Url)
+	assert.Equal(t, "https://b.com/", rules[1].Url)
+	assert.Equal(t, "https://www.raspberrypi.com/software/", rules[2].Url)
+}
+
+func TestCommandFilterAdHocIgnore(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{&mockMuffetExecutor{result: jsonReportOneError}})
+
+	ignoreListFile := "testdata/urlErrorIgnore.json"
+	ok := cf.Run([]string{"-i", ignoreListFile, "https://my-site.com"})
+	assert.False(t, ok)
+
+	stdout.Reset()
+	ok = cf.Run([]string{"-i", ignoreListFile, "--ignore", urlErrorLinkUrl + "=" + urlErrorLinkError, "https://my-site.com"})
+	assert.True(t, ok)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	t.Setenv(adHocIgnoresEnvVar, `[{"url": "`+urlErrorLinkUrl+`", "error": "`+urlErrorLinkError+`"}]`)
+	ok = cf.Run([]string{"-i", ignoreListFile, "https://my-site.com"})
+	assert.True(t, ok)
+}
@@ -21,6 +21,9 @@ const ignoresFilename = "ignores.json"
 
 var defaultIgnoresSuffix = configDir + "/" + ignoresFilename
 
+// alternateIgnoresFilenames are used when there is no default ignores file in a directory
+var alternateIgnoresFilenames = []string{"ignores.jsonc", "ignores.yaml", "ignores.yml"}
+
 func getDefaultIgnoresFile(prefix string) string {
 	return prefix + "/" + defaultIgnoresSuffix
 }
@@ -28,13 +31,21 @@ func getDefaultIgnoresFile(prefix string) string {
 type arguments struct {
 	MuffetPath        string   `short:"m" long:"muffet-path" description:"Path to muffet executable"`
 	MuffetJson        string   `short:"j" long:"input-json" description:"Path to muffet link check output file in json format"`
-	IgnoresJson       string   `short:"i" long:"ignores" description:"File containing url errors to ignore in json format. Defaults: .muffet-filter/ignores.json, ~/.muffet-filter/ignores.json"`
+	IgnoresJson       []string `short:"i" long:"ignores" description:"File or http(s) url containing url errors to ignore in json, jsonc or yaml format. Can be repeated. Defaults: .muffet-filter/ignores.json in the current dir and its parents up to the git root, ~/.muffet-filter/ignores.json, $MUFFET_FILTER_ORG_IGNORES"`
+	Ignore            []string `long:"ignore" description:"Ad hoc ignore rule url-pattern=error-pattern, with \\= for a = in the url pattern, in addition to the ignores files. Can be repeated. Also read from $MUFFET_FILTER_IGNORES"`
 	Verbose           bool     `short:"v" long:"verbose" description:"Show more output"`
 	Help              bool     `short:"h" long:"help" description:"Show this help"`
 	Version           bool     `long:"version" description:"Show version"`
 	MuffetArg         []string `long:"muffet-arg" description:"Additional argument passed to muffet executable."`
 	IgnoreEmptyErrUrl bool     `long:"ignore-empty-err-url" description:"Ignore empty URL field in error links (only use for special cases)"`
+	Normalize         bool     `long:"normalize" description:"Normalize link and page urls before matching: lowercase the host, drop default ports, convert IDN hosts to punycode, normalize percent-encoding, and merge duplicate pages"`
+	StripQuery        []string `long:"strip-query" description:"Remove the query parameter from urls, e.g. lang, or * to remove the whole query. Can be repeated. Implies --normalize"`
+	SortQuery         bool     `long:"sort-query" description:"Sort the query parameters of urls by name. Implies --normalize"`
+	StripFragment     bool     `long:"strip-fragment" description:"Remove the #fragment from urls. Implies --normalize"`
+	ExcludeIgnored    bool     `long:"exclude-ignored" description:"Pass the rules ignoring any error on another host to muffet as --exclude patterns, so these links are not requested at all"`
 	URL               string
+	// newIgnoresFile is the ignores file a subcommand adds rules to, which has no rules while it does not exist yet
+	newIgnoresFile string
 }
 
 func getArguments(ss []string) (*arguments, error) {
@@ -48,6 +59,9 @@ func getArguments(ss []string) (*arguments, error) {
 
 	if args.Version || args.Help {
 		return &args, nil
+	} else if len(remaining) == 0 && args.MuffetJson != "" {
+		// the report is read from the file, so no url is needed
+		return &args, nil
 	} else if len(remaining) != 1 {
 		return nil, fmt.Errorf("invalid number of arguments\n\n%s", help())
 	}
@@ -65,7 +79,63 @@ func help() string {
 	// This seems to be a bug in go-flags. Was this fixed???
 	//_, _ = p.Parse() // nolint:errcheck
 
+	b := &bytes.Buffer{}
+	p.WriteHelp(b)
+	b.WriteString("\nCommands:\n")
+	for _, command := range subcommands() {
+		_, _ = fmt.Fprintf(b, "  %-10s %s\n", command.name, command.description)
+	}
+	return b.String()
+}
+
+// parseCommandArguments parses the options of a subcommand into data, which embeds the common arguments.
+// The url of the website to check is optional when a muffet report is given via --input-json.
+func parseCommandArguments(data any, common *arguments, usage string, ss []string) error {
+	remaining, err := flags.NewParser(data, flags.PassDoubleDash).ParseArgs(ss)
+	if err != nil {
+		return err
+	}
+
+	if common.Version || common.Help {
+		return nil
+	} else if len(remaining) == 0 && common.MuffetJson != "" {
+		return nil
+	} else if len(remaining) != 1 {
+		return fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(data, usage))
+	}
+
+	common.URL = remaining[0]
+	return nil
+}
+
+func commandHelp(data any, usage string) string {
+	p := flags.NewParser(data, flags.PassDoubleDash)
+	p.Usage = usage
+
 	b := &bytes.Buffer{}
 	p.WriteHelp(b)
 	return b.String()
 }
+
+// subcommand is run instead of the default link check when given as the first argument, e.g. "muffet-filter triage <url>"
+type subcommand struct {
+	name, description string
+	run               func(c *commandFilter, ss []string) (bool, error)
+}
+
+// subcommands returns the subcommands, in the order of the help.
+func subcommands() []subcommand {
+	return []subcommand{
+		{"triage", "Walk through the broken links, and add ignore rules for them interactively", (*commandFilter).runTriage},
+		{"baseline", "Add ignore rules for all current broken links, so later runs only fail on new broken links", (*commandFilter).runBaseline},
+		{"suggest", "Suggest generalized ignore rules by clustering the broken links", (*commandFilter).runSuggest},
+		{"explain", "Show why a broken link is, or is not, ignored by each rule", (*commandFilter).runExplain},
+		{"lint", "Check ignores files for invalid, duplicate, shadowed and overly broad rules", (*commandFilter).runLint},
+		{"fmt", "Sort ignores files, remove duplicate rules, and indent them consistently", (*commandFilter).runFmt},
+		{"schema", "Print the json schema of ignores files, or of the report", (*commandFilter).runSchema},
+		{"ignores", "Add, remove or list the rules of an ignores file", (*commandFilter).runIgnores},
+		{"import", "Convert the exclusions of lychee, markdown-link-check and htmltest into ignore rules", (*commandFilter).runImport},
+		{"test", "Check that broken links of a cases file are ignored or reported as expected", (*commandFilter).runTest},
+		{"simulate", "Show which links of stored reports a change of the ignores files suppresses or unsuppresses", (*commandFilter).runSimulate},
+	}
+}
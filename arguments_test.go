@@ -104,3 +104,10 @@ func TestHelpFunc(t *testing.T) {
 	helpText := help()
 	assert.Contains(t, helpText, "[options] <url of website to check>")
 }
+
+func TestGetArgumentsInputJsonWithoutUrl(t *testing.T) {
+	args, err := getArguments([]string{"-j", "report.json"})
+	assert.Nil(t, err)
+	assert.Equal(t, "report.json", args.MuffetJson)
+	assert.Equal(t, "", args.URL)
+}
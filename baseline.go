@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type baselineArguments struct {
+	arguments
+	Expires string `long:"expires" description:"Expiry date (YYYY-MM-DD), or number of days (e.g. 90d), after which the baseline rules no longer apply"`
+	PerPage bool   `long:"per-page" description:"Only ignore each broken link on the pages where it is currently found"`
+}
+
+const baselineUsage = "baseline [options] <url of website to check>"
+
+// baselineReason is the reason of all rules added by the baseline subcommand.
+const baselineReason = "baseline"
+
+var expiresInDays = regexp.MustCompile(`^(\d+)d$`)
+
+// getExpiryDate converts the --expires argument into a date.
+func getExpiryDate(expires string, today time.Time) (string, error) {
+	if expires == "" {
+		return "", nil
+	}
+	if match := expiresInDays.FindStringSubmatch(expires); match != nil {
+		days, _ := strconv.Atoi(match[1])
+		return today.AddDate(0, 0, days).Format(dateLayout), nil
+	}
+	if _, err := time.Parse(dateLayout, expires); err != nil {
+		return "", fmt.Errorf("invalid expiry: %s, expected a date (YYYY-MM-DD) or a number of days (e.g. 90d)", expires)
+	}
+	return expires, nil
+}
+
+// getBaselineRules returns an exact match rule for each distinct broken link in the report.
+func getBaselineRules(report Report, perPage bool, today time.Time, expires string) (rules []IgnoreRule) {
+	seen := map[IgnoreRule]bool{}
+	for _, errorLink := range report.errorLinks() {
+		rule := IgnoreRule{Url: literalPattern(errorLink.Link.Url), Error: literalPattern(errorLink.Link.Error)}
+		if perPage {
+			rule.Page = literalPattern(errorLink.Page)
+		}
+		if seen[rule] {
+			continue
+		}
+		seen[rule] = true
+
+		rule.Reason = baselineReason
+		rule.Added = today.Format(dateLayout)
+		rule.Expires = expires
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// runBaseline checks the website, or reads the report given via --input-json, and adds an ignore rule for each
+// broken link that is not ignored yet. Later runs then only fail on new broken links.
+func (c *commandFilter) runBaseline(ss []string) (bool, error) {
+	args := baselineArguments{}
+	if err := parseCommandArguments(&args, &args.arguments, baselineUsage, ss); err != nil {
+		return false, err
+	} else if args.Help || args.Version {
+		c.print(commandHelp(&baselineArguments{}, baselineUsage))
+		return true, nil
+	}
+
+	today := now()
+	expires, err := getExpiryDate(args.Expires, today)
+	if err != nil {
+		return false, err
+	}
+	ignoreListFile, err := getIgnoresFileToEdit(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+	allowNewIgnoresFile(&args.arguments, ignoreListFile)
+	reportFiltered, err := c.checkAndFilter(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+
+	rules := getBaselineRules(reportFiltered, args.PerPage, today, expires)
+	if len(rules) == 0 {
+		c.print("no broken links, nothing to add to the baseline")
+		return true, nil
+	}
+	if err = addIgnoreRules(ignoreListFile, rules); err != nil {
+		return false, err
+	}
+	c.print(fmt.Sprintf("added %d baseline rules to: %s", len(rules), ignoreListFile))
+	return true, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var baselineToday = time.Date(2026, 3, 15, 10, 0, 0, 0, time.Local)
+
+func TestGetExpiryDate(t *testing.T) {
+	expires, err := getExpiryDate("", baselineToday)
+	assert.Nil(t, err)
+	assert.Equal(t, "", expires)
+
+	expires, err = getExpiryDate("90d", baselineToday)
+	assert.Nil(t, err)
+	assert.Equal(t, "2026-06-13", expires)
+
+	expires, err = getExpiryDate("2026-12-31", baselineToday)
+	assert.Nil(t, err)
+	assert.Equal(t, "2026-12-31", expires)
+
+	_, err = getExpiryDate("next year", baselineToday)
+	assert.EqualError(t, err, "invalid expiry: next year, expected a date (YYYY-MM-DD) or a number of days (e.g. 90d)")
+}
+
+func TestGetBaselineRules(t *testing.T) {
+	origNow := now
+	defer func() {
+		now = origNow
+	}()
+	now = func() time.Time { return baselineToday }
+
+	report, err := (&parseResponse{jsonReportSameErrorTwoPages}).loadReport(&arguments{})
+	assert.Nil(t, err)
+
+	expected := IgnoreRule{
+		Url:    `^https://help\.sonatype\.com/index\.html#content-wrapper$`,
+		Error:  `^id #content-wrapper not found$`,
+		Reason: "baseline",
+		Added:  "2026-03-15",
+	}
+	assert.Equal(t, []IgnoreRule{expected}, getBaselineRules(report, false, baselineToday, ""))
+
+	rules := getBaselineRules(report, true, baselineToday, "2026-04-01")
+	assert.Equal(t, 2, len(rules))
+	assert.Equal(t, `^https://help\.sonatype\.com/search\.html$`, rules[0].Page)
+	assert.Equal(t, `^https://help\.sonatype\.com/index\.html$`, rules[1].Page)
+	assert.Equal(t, "2026-04-01", rules[1].Expires)
+
+	// the baseline rules ignore all links of the report
+	reportFiltered, err := report.filter(rules, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(reportFiltered.UrlsToCheck))
+}
+
+func TestBaseline(t *testing.T) {
+	origNow := now
+	defer func() {
+		now = origNow
+	}()
+	now = func() time.Time { return baselineToday }
+
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"baseline", "-i", ignoreListFile, "-j", "testdata/reportErrorsOnly.json", "--expires", "30d"})
+	assert.True(t, ok)
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "added 811 baseline rules to: "+ignoreListFile)
+
+	content, err := os.ReadFile(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), `- url: ^https://help\.sonatype\.com/index\.html#content-wrapper$
+  error: '^id #content-wrapper not found$'
+  reason: baseline
+  added: "2026-03-15"
+  expires: "2026-04-14"
+`)
+
+	// the report has no new broken links
+	stdout.Reset()
+	ok = cf.Run([]string{"-i", ignoreListFile, "-j", "testdata/reportErrorsOnly.json"})
+	assert.True(t, ok)
+	assert.Empty(t, stdout.String())
+
+	stdout.Reset()
+	ok = cf.Run([]string{"baseline", "-i", ignoreListFile, "-j", "testdata/reportErrorsOnly.json"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "no broken links, nothing to add to the baseline")
+
+	// the baseline expires
+	now = func() time.Time { return baselineToday.AddDate(0, 1, 0) }
+	stdout.Reset()
+	ok = cf.Run([]string{"-i", ignoreListFile, "-j", "testdata/reportErrorsOnly.json"})
+	assert.False(t, ok)
+	assert.Contains(t, stdout.String(), "content-wrapper")
+}
+
+func TestBaselineErrors(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"baseline", "-j", "testdata/reportErrorsOnly.json", "--expires", "soon"})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "invalid expiry: soon")
+
+	stderr.Reset()
+	ok = cf.Run([]string{"baseline"})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "invalid number of arguments")
+	assert.Contains(t, stderr.String(), "baseline [options] <url of website to check>")
+
+	ok = cf.Run([]string{"baseline", "--help"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "--per-page")
+}
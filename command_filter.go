@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/logrusorgru/aurora/v3"
@@ -13,10 +14,12 @@ type commandFilter struct {
 	stdout, stderr io.Writer
 	terminal       bool
 	factory        muffetFactory
+	// stdin is read by interactive subcommands
+	stdin io.Reader
 }
 
 func newCommandFilter(stdout, stderr io.Writer, terminal bool, f muffetFactory) *commandFilter {
-	return &commandFilter{stdout, stderr, terminal, f}
+	return &commandFilter{stdout, stderr, terminal, f, os.Stdin}
 }
 
 func (c *commandFilter) Run(args []string) bool {
@@ -29,6 +32,14 @@ func (c *commandFilter) Run(args []string) bool {
 }
 
 func (c *commandFilter) runWithError(ss []string) (bool, error) {
+	if len(ss) > 0 {
+		for _, command := range subcommands() {
+			if command.name == ss[0] {
+				return command.run(c, ss[1:])
+			}
+		}
+	}
+
 	args, err := getArguments(ss)
 	if err != nil {
 		return false, err
@@ -40,47 +51,104 @@ func (c *commandFilter) runWithError(ss []string) (bool, error) {
 		return true, nil
 	}
 
+	reportFiltered, err := c.checkAndFilter(args)
+	if err != nil {
+		return false, err
+	}
+	if len(reportFiltered.UrlsToCheck) > 0 || len(reportFiltered.Findings) > 0 {
+		prettyJson, err := json.MarshalIndent(reportFiltered, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		_, _ = c.stdout.Write(prettyJson)
+		_, _ = fmt.Fprintln(c.stdout)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// check calls muffet to check the website, or reads the report given via --input-json, and parses the json report.
+// muffetArgs are passed to muffet in addition to the --muffet-arg options, e.g. to also report the successful links.
+// The urls of the report are normalized on request.
+func (c *commandFilter) check(args *arguments, muffetArgs []string) (report Report, err error) {
+	if report, err = c.checkRaw(args, muffetArgs); err != nil {
+		return
+	}
+	if options := args.getNormalizeOptions(); options != nil {
+		report = report.normalize(options)
+	}
+	return
+}
+
+func (c *commandFilter) checkRaw(args *arguments, muffetArgs []string) (report Report, err error) {
+	if args.MuffetJson != "" {
+		var jsonReport []byte
+		if jsonReport, err = os.ReadFile(args.MuffetJson); err != nil {
+			return
+		}
+		parseReport := parseResponse{string(jsonReport)}
+		return parseReport.loadReport(args)
+	}
+
 	// call muffet to generate json response
 	options := muffetOptions{arguments: defaultOptions}
 	if len(args.MuffetArg) != 0 {
 		options.arguments = append(options.arguments, args.MuffetArg...)
 	}
+	options.arguments = append(options.arguments, muffetArgs...)
 	options.arguments = append(options.arguments, args.URL)
 	muffetExec := c.factory.Create(options)
 	jsonReport, err := muffetExec.Check(args)
 	if err != nil {
-		return false, err
+		return
 	}
 
 	// read json file into struct
 	parseReport := parseResponse{jsonReport}
-	report, err := parseReport.loadReport(args)
-	if err != nil {
-		return false, err
-	}
+	return parseReport.loadReport(args)
+}
 
-	// filter out matching errors
+// checkAndFilter checks the website, removes the errors matching the ignore rules from the report, and adds
+// findings for the failed expectations.
+func (c *commandFilter) checkAndFilter(args *arguments) (reportFiltered Report, err error) {
 	// load errorsToIgnore from on disk config and/or args
-	errorsToIgnore, err := loadIgnoreList(args)
+	ignores, err := loadIgnores(args)
 	if err != nil {
-		return false, err
+		return
 	}
 
-	reportFiltered, err := report.filter(errorsToIgnore, args.Verbose)
+	// muffet only reports the successful links on request, but they are needed to check the expectations
+	withSuccessLinks := len(ignores.Expectations) > 0 && args.MuffetJson == "" &&
+		!hasMuffetVerboseOption(args.MuffetArg)
+	var muffetArgs []string
+	if withSuccessLinks {
+		muffetArgs = append(muffetArgs, muffetVerboseOption)
+	}
+	// links of rules ignoring any error on another host need not be requested at all
+	if args.ExcludeIgnored && args.MuffetJson == "" {
+		muffetArgs = append(muffetArgs, getCrawlExclusions(ignores, args)...)
+	}
+	report, err := c.check(args, muffetArgs)
 	if err != nil {
-		return false, err
+		return
 	}
-	if len(reportFiltered.UrlsToCheck) > 0 {
-		prettyJson, err := json.MarshalIndent(reportFiltered, "", "  ")
-		if err != nil {
-			return false, err
-		}
-		_, _ = c.stdout.Write(prettyJson)
-		_, _ = fmt.Fprintln(c.stdout)
-		return false, nil
+	if len(ignores.Expectations) > 0 && args.MuffetJson != "" && !report.hasSuccessLinks() {
+		// without the successful links, every expected link would be reported as not found
+		return reportFiltered, fmt.Errorf("%s has no successful links, which are needed to check the expectations: "+
+			"the report was not produced with muffet --verbose", args.MuffetJson)
 	}
 
-	return true, nil
+	// filter out matching errors
+	if reportFiltered, err = report.filter(ignores.Rules, args.Verbose); err != nil {
+		return
+	}
+	// expectations are checked against the whole report, so an ignored error still fails its expectation
+	reportFiltered.Findings = append(reportFiltered.Findings, report.checkExpectations(ignores.Expectations)...)
+	if withSuccessLinks {
+		reportFiltered = reportFiltered.withoutSuccessLinks()
+	}
+	return
 }
 
 func (c *commandFilter) print(xs ...any) {
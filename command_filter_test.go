@@ -174,3 +174,26 @@ func TestCommandFilter_LoadReportError(t *testing.T) {
 	assert.False(t, ok)
 	assert.Contains(t, stderr.String(), "invalid character")
 }
+
+func TestCommandFilter_InputJson(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"-i", "testdata/urlErrorIgnore.json", "-j", "testdata/reportSuccessOnly.json"})
+
+	assert.False(t, ok)
+	assert.Contains(t, stdout.String(), "https://www.google.com/")
+	assert.Empty(t, stderr.String())
+}
+
+func TestCommandFilter_InputJsonMissing(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"-j", "no-such-report.json"})
+
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "open no-such-report.json: no such file or directory")
+}
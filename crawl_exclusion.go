@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// muffetExcludeOption makes muffet skip the links matching a regular expression, without requesting them.
+const muffetExcludeOption = "--exclude="
+
+// hostPatternPrefix matches url patterns anchored at the scheme and an exact host, e.g. "^https://twitter\.com/", or
+// the patterns of hostPattern. The host must be followed by a port, a path, a query, a fragment or the end of the url.
+var hostPatternPrefix = regexp.MustCompile(`^\^(?:https\?|https|http)://((?:[a-zA-Z0-9-]|\\\.)+)(?::[0-9]+)?(?:/|\\\?|#|\$|\(\[/\?#\]\|\$\))`)
+
+// getPatternHost returns the host, which all urls matching the pattern are on, if the pattern is anchored at it.
+// Patterns with alternatives after the host are rejected, as a top-level alternative, e.g. "^https://a\.com/|b", is not
+// anchored.
+func getPatternHost(pattern string) (string, bool) {
+	match := hostPatternPrefix.FindStringSubmatch(pattern)
+	if match == nil || strings.Contains(pattern[len(match[0]):], "|") || compilePattern(pattern) == nil {
+		return "", false
+	}
+	return strings.ToLower(strings.ReplaceAll(match[1], `\.`, ".")), true
+}
+
+// getCrawlExclusion returns why the rule has to run as a post-filter, or "" if muffet can skip its links instead.
+// Only links on other hosts are skipped, because muffet neither checks nor crawls an excluded url, so excluding a
+// page of the website would drop the links on it from the check.
+func (rule *IgnoreRule) getCrawlExclusion(siteHost string, expectations []Expectation) string {
+	switch {
+	case rule.isExpired():
+		return "expired"
+	case rule.Error != "" && rule.Error != ".*":
+		return "matches only some errors"
+	case rule.Page != "" || rule.PageExclude != "":
+		return "matches only on some pages"
+	case rule.Status != "" || rule.Category != "" || rule.When != "":
+		return "matches only some errors"
+	case rule.hasThreshold():
+		return "has a threshold, which counts the links"
+	}
+	host, ok := getPatternHost(rule.Url)
+	if !ok {
+		return "url pattern is not anchored at a host"
+	} else if host == siteHost {
+		return "url pattern matches the website"
+	}
+	for _, expectation := range expectations {
+		// an expectation on an unknown host might need the links of any host
+		if expectationHost, ok := getPatternHost(expectation.Url); !ok {
+			return "an expectation url pattern is not anchored at a host"
+		} else if expectationHost == host {
+			return "an expectation needs its links"
+		}
+	}
+	return ""
+}
+
+// getCrawlExclusions returns the --exclude options of muffet for the rules, which ignore any error of the links on
+// another host. These rules still run as post-filters too, in case muffet reports a link in a different form, e.g.
+// with --normalize.
+func getCrawlExclusions(ignores ignoreFile, args *arguments) (options []string) {
+	siteUrl, err := url.Parse(args.URL)
+	if err != nil || siteUrl.Host == "" {
+		log.Printf("crawl exclusions need the url of the website to check, got: %s", args.URL)
+		return
+	}
+	siteHost := strings.ToLower(siteUrl.Hostname())
+
+	postFilters := 0
+	for i := range ignores.Rules {
+		rule := &ignores.Rules[i]
+		if reason := rule.getCrawlExclusion(siteHost, ignores.Expectations); reason != "" {
+			postFilters++
+			if args.Verbose {
+				fmt.Printf("post-filter: %s (url: %s): %s\n", rule.origin(), rule.Url, reason)
+			}
+			continue
+		}
+		options = append(options, muffetExcludeOption+rule.Url)
+		if args.Verbose {
+			fmt.Printf("pushed down to muffet: %s (url: %s)\n", rule.origin(), rule.Url)
+		}
+	}
+	log.Printf("crawl exclusions: %d rules pushed down to muffet, %d rules only run as post-filters", len(options), postFilters)
+	return
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPatternHost(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		`^https://twitter\.com/`:            "twitter.com",
+		`^http://WWW\.Example\.com$`:        "www.example.com",
+		`^https://partner\.com:8443/api`:    "partner.com",
+		hostPattern("https://cdn.com/a.js"): "cdn.com",
+	} {
+		host, ok := getPatternHost(pattern)
+		assert.True(t, ok, pattern)
+		assert.Equal(t, expected, host, pattern)
+	}
+	for _, pattern := range []string{
+		`https://twitter\.com/`,
+		`^https://twitter\.com`,
+		`^https://.*\.twitter\.com/`,
+		`^https://twitter\.com/|/docs/`,
+		`^https://twitter\.com/(`,
+	} {
+		_, ok := getPatternHost(pattern)
+		assert.False(t, ok, pattern)
+	}
+}
+
+func TestGetCrawlExclusion(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local) }
+
+	expectations := []Expectation{{Url: `^https://status\.partner\.com/`}}
+	for expected, rule := range map[string]IgnoreRule{
+		"":                           {Url: `^https://twitter\.com/`},
+		"expired":                    {Url: `^https://twitter\.com/`, Expires: "2026-10-18"},
+		"matches only some errors":   {Url: `^https://twitter\.com/`, Error: "429"},
+		"matches only on some pages": {Url: `^https://twitter\.com/`, Page: "/blog/"},
+		"has a threshold, which counts the links": {Url: `^https://twitter\.com/`, MaxOccurrences: 3},
+		"url pattern is not anchored at a host":   {Url: `twitter\.com`},
+		"url pattern matches the website":         {Url: `^https://my-site\.com/old/`},
+		"an expectation needs its links":          {Url: `^https://status\.partner\.com/`, Error: ".*"},
+	} {
+		assert.Equal(t, expected, rule.getCrawlExclusion("my-site.com", expectations), rule.Url)
+	}
+
+	// the expectation is on another host, which merely ends with the host of the rule
+	rule := IgnoreRule{Url: `^https://partner\.com/`}
+	assert.Equal(t, "", rule.getCrawlExclusion("my-site.com", expectations))
+	assert.Equal(t, "an expectation url pattern is not anchored at a host",
+		rule.getCrawlExclusion("my-site.com", []Expectation{{Url: `status\.partner\.com/`}}))
+}
+
+func TestCommandFilterExcludeIgnored(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[
+  {"url": "^https://partner\\.com/"},
+  {"url": "https://my-site.com/pricing", "error": "503"}
+]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	report := `[{"url": "https://my-site.com/", "links": [
+  {"url": "https://partner.com/", "error": "503"},
+  {"url": "https://my-site.com/pricing", "error": "503"}
+]}]`
+	factory := &optionsMuffetFactory{mockMuffetFactory: mockMuffetFactory{&mockMuffetExecutor{result: report}}}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, factory)
+
+	ok := cf.Run([]string{"-i", ignoreListFile, "--exclude-ignored", "https://my-site.com/"})
+	assert.True(t, ok)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, factory.options.arguments, `--exclude=^https://partner\.com/`)
+	assert.Equal(t, "https://my-site.com/", factory.options.arguments[len(factory.options.arguments)-1])
+
+	// without the option, all links are requested
+	ok = cf.Run([]string{"-i", ignoreListFile, "https://my-site.com/"})
+	assert.True(t, ok)
+	assert.NotContains(t, factory.options.arguments, `--exclude=^https://partner\.com/`)
+}
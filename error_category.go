@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errorCategory is the structured kind of error parsed from the free-text error muffet reports for a link.
+type errorCategory string
+
+const (
+	categoryHttpStatus        errorCategory = "http-status"
+	categoryTimeout           errorCategory = "timeout"
+	categoryDns               errorCategory = "dns"
+	categoryTls               errorCategory = "tls"
+	categoryConnectionRefused errorCategory = "connection-refused"
+	categoryFragmentNotFound  errorCategory = "fragment-not-found"
+	categoryRedirectLoop      errorCategory = "redirect-loop"
+	categoryOther             errorCategory = "other"
+)
+
+var errorCategories = []errorCategory{
+	categoryHttpStatus,
+	categoryTimeout,
+	categoryDns,
+	categoryTls,
+	categoryConnectionRefused,
+	categoryFragmentNotFound,
+	categoryRedirectLoop,
+	categoryOther,
+}
+
+// muffet appends the redirect target to errors of redirected links, e.g. "403 (following redirect https://foo.com/)"
+var followingRedirectSuffix = regexp.MustCompile(`\s*\(following redirect .*\)$`)
+var fragmentNotFoundError = regexp.MustCompile(`^id #.* not found$`)
+var httpStatusError = regexp.MustCompile(`^([1-5]\d\d|999)$`)
+
+// classifyError parses a muffet error message into a category, and the http status code if there is one.
+func classifyError(message string) (category errorCategory, status int) {
+	message = strings.TrimSpace(message)
+	// check for fragments first, because the id can contain any text
+	if fragmentNotFoundError.MatchString(message) {
+		return categoryFragmentNotFound, 0
+	}
+
+	message = followingRedirectSuffix.ReplaceAllString(message, "")
+	if httpStatusError.MatchString(message) {
+		status, _ = strconv.Atoi(message)
+		return categoryHttpStatus, status
+	}
+
+	lowerMessage := strings.ToLower(message)
+	switch {
+	case strings.Contains(lowerMessage, "redirect loop"),
+		strings.Contains(lowerMessage, "too many redirects"):
+		return categoryRedirectLoop, 0
+	case strings.Contains(lowerMessage, "no such host"),
+		strings.Contains(lowerMessage, "server misbehaving"),
+		strings.Contains(lowerMessage, "lookup "):
+		return categoryDns, 0
+	case strings.Contains(lowerMessage, "x509:"),
+		strings.Contains(lowerMessage, "tls:"),
+		strings.Contains(lowerMessage, "certificate"):
+		return categoryTls, 0
+	case strings.Contains(lowerMessage, "connection refused"):
+		return categoryConnectionRefused, 0
+	case strings.Contains(lowerMessage, "timeout"),
+		strings.Contains(lowerMessage, "timed out"),
+		strings.Contains(lowerMessage, "deadline exceeded"):
+		return categoryTimeout, 0
+	}
+	return categoryOther, 0
+}
+
+// isStatusMatch reports whether the status code matches the status pattern of an ignore rule.
+// The pattern is a comma separated list of status codes or classes, e.g. "404", "5xx" or "401,403".
+func isStatusMatch(pattern string, status int) bool {
+	if status == 0 {
+		return false
+	}
+	code := strconv.Itoa(status)
+	for _, part := range strings.Split(pattern, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if len(part) != len(code) {
+			continue
+		}
+		match := true
+		for i := 0; i < len(part); i++ {
+			if part[i] != 'x' && part[i] != code[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// plainUrlErrorLink has no MarshalJSON method, so it is marshaled by its fields.
+type plainUrlErrorLink UrlErrorLink
+
+// urlErrorLinkOutput is the json output of an error link. The status code is written as "statusCode", because a
+// "status" field marks a success link in muffet reports.
+type urlErrorLinkOutput struct {
+	plainUrlErrorLink
+	Category   errorCategory `json:"category"`
+	StatusCode int           `json:"statusCode,omitempty"`
+}
+
+// MarshalJSON adds the parsed error category and status code to the json output of the error link.
+func (errorLink UrlErrorLink) MarshalJSON() ([]byte, error) {
+	category, status := classifyError(errorLink.Error)
+	return json.Marshal(urlErrorLinkOutput{plainUrlErrorLink(errorLink), category, status})
+}
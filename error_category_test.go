@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, tc := range []struct {
+		message  string
+		category errorCategory
+		status   int
+	}{
+		{"404", categoryHttpStatus, 404},
+		{"999", categoryHttpStatus, 999},
+		{"403 (following redirect https://support.sonatype.com/hc/en-us)", categoryHttpStatus, 403},
+		{"timeout", categoryTimeout, 0},
+		{"timeout (following redirect https://sonatype-download.global.ssl.fastly.net/nexus-3.64.0-04-mac.tgz)", categoryTimeout, 0},
+		{"dialing to the given TCP address timed out", categoryTimeout, 0},
+		{"dial tcp4 127.0.0.1:5003: i/o timeout", categoryTimeout, 0},
+		{"dial tcp: lookup foo.invalid: no such host", categoryDns, 0},
+		{"x509: certificate signed by unknown authority", categoryTls, 0},
+		{"remote error: tls: handshake failure", categoryTls, 0},
+		{"dial tcp4 127.0.0.1:8081: connect: connection refused", categoryConnectionRefused, 0},
+		{"id #content-wrapper not found", categoryFragmentNotFound, 0},
+		{"id #timeout not found", categoryFragmentNotFound, 0},
+		{"too many redirects detected when doing the request", categoryRedirectLoop, 0},
+		{"body size exceeds the given limit", categoryOther, 0},
+		{"5003", categoryOther, 0},
+		{"", categoryOther, 0},
+	} {
+		category, status := classifyError(tc.message)
+		assert.Equal(t, tc.category, category, tc.message)
+		assert.Equal(t, tc.status, status, tc.message)
+	}
+}
+
+func TestIsStatusMatch(t *testing.T) {
+	assert.Equal(t, true, isStatusMatch("404", 404))
+	assert.Equal(t, true, isStatusMatch("5xx", 503))
+	assert.Equal(t, true, isStatusMatch("5XX", 500))
+	assert.Equal(t, true, isStatusMatch("401, 403", 403))
+	assert.Equal(t, true, isStatusMatch("4xx,5xx", 429))
+	assert.Equal(t, false, isStatusMatch("5xx", 404))
+	assert.Equal(t, false, isStatusMatch("5xx", 0))
+	assert.Equal(t, false, isStatusMatch("50", 500))
+	assert.Equal(t, false, isStatusMatch("", 500))
+}
+
+func TestUrlErrorLinkMarshalJSON(t *testing.T) {
+	jsonLink, err := json.Marshal(UrlErrorLink{Url: "https://foo.com", Error: "503"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"url":"https://foo.com","error":"503","category":"http-status","statusCode":503}`, string(jsonLink))
+
+	jsonLink, err = json.Marshal(UrlErrorLink{Url: "https://foo.com", Error: "timeout"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"url":"https://foo.com","error":"timeout","category":"timeout"}`, string(jsonLink))
+}
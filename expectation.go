@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Expectation is a link, which must be found on the website. Url is matched against the links of all pages, and Page
+// (optional) against the pages on which the link must appear. The link must not be broken, and if Status is given
+// (e.g. "200" or "2xx"), its status must match. Expectations also prove that the website was actually checked.
+type Expectation struct {
+	Url    string `json:"url" yaml:"url"`
+	Page   string `json:"page,omitempty" yaml:"page,omitempty"`
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+const findingExpectation = "expectation"
+
+// Finding is a problem of the report, which is not a broken link, e.g. a failed expectation.
+type Finding struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// muffetVerboseOption makes muffet report the successful links too, which are needed to check expectations.
+const (
+	muffetVerboseOption      = "--verbose"
+	muffetVerboseShortOption = "-v"
+)
+
+// hasMuffetVerboseOption reports whether the muffet arguments already request the successful links.
+func hasMuffetVerboseOption(muffetArgs []string) bool {
+	return slices.Contains(muffetArgs, muffetVerboseOption) || slices.Contains(muffetArgs, muffetVerboseShortOption)
+}
+
+// hasSuccessLinks reports whether the report has successful links, which muffet only reports with --verbose.
+func (rep *Report) hasSuccessLinks() bool {
+	for _, urlToCheck := range rep.UrlsToCheck {
+		for _, link := range urlToCheck.Links {
+			if _, ok := link.(UrlSuccessLink); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkExpectations returns a finding for each expected link, which is missing, broken or has an unexpected status.
+func (rep *Report) checkExpectations(expectations []Expectation) (findings []Finding) {
+	for _, expectation := range expectations {
+		findings = append(findings, rep.checkExpectation(expectation)...)
+	}
+	return
+}
+
+func (rep *Report) checkExpectation(expectation Expectation) (findings []Finding) {
+	newFinding := func(format string, a ...any) {
+		findings = append(findings, Finding{Kind: findingExpectation, Message: fmt.Sprintf(format, a...)})
+	}
+
+	found := false
+	for _, urlToCheck := range rep.UrlsToCheck {
+		if expectation.Page != "" && !isPatternMatch(expectation.Page, urlToCheck.Url) {
+			continue
+		}
+		for _, link := range urlToCheck.Links {
+			switch v := link.(type) {
+			case UrlErrorLink:
+				if isPatternMatch(expectation.Url, v.Url) {
+					found = true
+					newFinding("expected link %s on page %s is broken: %s", v.Url, urlToCheck.Url, v.Error)
+				}
+			case UrlSuccessLink:
+				if isPatternMatch(expectation.Url, v.Url) {
+					found = true
+					if expectation.Status != "" && !isStatusMatch(expectation.Status, v.Status) {
+						newFinding("expected link %s on page %s has status %d, expected: %s", v.Url, urlToCheck.Url, v.Status, expectation.Status)
+					}
+				}
+			}
+		}
+	}
+
+	if !found && expectation.Page != "" {
+		newFinding("expected link %s not found on any page matching: %s", expectation.Url, expectation.Page)
+	} else if !found {
+		newFinding("expected link %s not found on any page", expectation.Url)
+	}
+	return
+}
+
+// withoutSuccessLinks returns the report without the successful links, and without the pages having no other links.
+func (rep *Report) withoutSuccessLinks() Report {
+	report := Report{Findings: rep.Findings}
+	for _, urlToCheck := range rep.UrlsToCheck {
+		var links []interface{}
+		for _, link := range urlToCheck.Links {
+			if _, ok := link.(UrlSuccessLink); !ok {
+				links = append(links, link)
+			}
+		}
+		if len(links) > 0 {
+			report.UrlsToCheck = append(report.UrlsToCheck, UrlToCheck{Url: urlToCheck.Url, Links: links})
+		}
+	}
+	return report
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonReportExpectations = `[
+  {
+    "url": "https://my-site.com/install",
+    "links": [
+      {"url": "https://my-site.com/download/tool.zip", "status": 200},
+      {"url": "https://my-site.com/pricing", "status": 301},
+      {"url": "https://partner.com/", "error": "503"}
+    ]
+  },
+  {
+    "url": "https://my-site.com/",
+    "links": [
+      {"url": "https://my-site.com/pricing", "status": 200}
+    ]
+  }
+]`
+
+func loadExpectationsReport(t *testing.T) Report {
+	report, err := (&parseResponse{jsonReportExpectations}).loadReport(&arguments{})
+	assert.Nil(t, err)
+	return report
+}
+
+func TestCheckExpectationsPass(t *testing.T) {
+	report := loadExpectationsReport(t)
+	assert.Nil(t, report.checkExpectations([]Expectation{
+		{Url: "https://my-site.com/download/tool.zip", Page: "https://my-site.com/install", Status: "200"},
+		{Url: "https://my-site.com/pricing"},
+		{Url: `^https://my-site\.com/download/.*\.zip$`, Status: "2xx"},
+	}))
+}
+
+func TestCheckExpectationsMissing(t *testing.T) {
+	report := loadExpectationsReport(t)
+	assert.Equal(t, []Finding{
+		{Kind: "expectation", Message: "expected link https://my-site.com/docs not found on any page"},
+		{Kind: "expectation", Message: "expected link https://my-site.com/download/tool.zip not found on any page matching: ^https://my-site\\.com/$"},
+	}, report.checkExpectations([]Expectation{
+		{Url: "https://my-site.com/docs"},
+		{Url: "https://my-site.com/download/tool.zip", Page: `^https://my-site\.com/$`},
+	}))
+
+	// an empty report proves the website was not checked
+	assert.Equal(t, 1, len((&Report{}).checkExpectations([]Expectation{{Url: "https://my-site.com/pricing"}})))
+}
+
+func TestCheckExpectationsBrokenAndStatus(t *testing.T) {
+	report := loadExpectationsReport(t)
+	assert.Equal(t, []Finding{
+		{Kind: "expectation", Message: "expected link https://partner.com/ on page https://my-site.com/install is broken: 503"},
+		{Kind: "expectation", Message: "expected link https://my-site.com/pricing on page https://my-site.com/install has status 301, expected: 200"},
+	}, report.checkExpectations([]Expectation{
+		{Url: "https://partner.com/"},
+		{Url: "https://my-site.com/pricing", Status: "200"},
+	}))
+}
+
+func TestDecodeIgnoreFileExpectations(t *testing.T) {
+	doc, err := parseIgnoreDocument("ignores.yaml", []byte(`
+rules: []
+expectations:
+  - url: https://my-site.com/pricing
+    status: 2xx
+`))
+	assert.Nil(t, err)
+	file, err := decodeIgnoreFile("ignores.yaml", doc)
+	assert.Nil(t, err)
+	assert.Equal(t, []Expectation{{Url: "https://my-site.com/pricing", Status: "2xx"}}, file.Expectations)
+
+	doc, err = parseIgnoreDocument("ignores.json", []byte(`{"expectations": [{"page": "https://my-site.com/"}]}`))
+	assert.Nil(t, err)
+	_, err = decodeIgnoreFile("ignores.json", doc)
+	assert.EqualError(t, err, "ignores.json:1:19: expectation 0: missing url")
+
+	doc, err = parseIgnoreDocument("ignores.json", []byte(`{"expectations": {}}`))
+	assert.Nil(t, err)
+	_, err = decodeIgnoreFile("ignores.json", doc)
+	assert.EqualError(t, err, "ignores.json:1:18: expected a list of expectations")
+}
+
+type optionsMuffetFactory struct {
+	mockMuffetFactory
+	options muffetOptions
+}
+
+func (m *optionsMuffetFactory) Create(options muffetOptions) muffetExecutor {
+	m.options = options
+	return m.executor
+}
+
+func TestCommandFilterExpectations(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`{
+  "rules": [{"url": "https://partner.com/", "error": "503"}],
+  "expectations": [{"url": "https://my-site.com/pricing", "page": "https://my-site.com/"}]
+}`), 0644))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	factory := &optionsMuffetFactory{mockMuffetFactory: mockMuffetFactory{&mockMuffetExecutor{result: jsonReportExpectations}}}
+	cf := newCommandFilter(stdout, stderr, false, factory)
+
+	ok := cf.Run([]string{"-i", ignoreListFile, "https://my-site.com/"})
+	assert.True(t, ok)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+	// muffet reports successful links too, to check the expectations
+	assert.Contains(t, factory.options.arguments, "--verbose")
+
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`{
+  "rules": [{"url": "https://partner.com/", "error": "503"}],
+  "expectations": [{"url": "https://my-site.com/signup"}]
+}`), 0644))
+	ok = cf.Run([]string{"-i", ignoreListFile, "https://my-site.com/"})
+	assert.False(t, ok)
+	assert.Contains(t, stdout.String(), `"Findings": [
+    {
+      "kind": "expectation",
+      "message": "expected link https://my-site.com/signup not found on any page"
+    }
+  ]`)
+}
+
+func TestCommandFilterExpectationsMuffetVerbose(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`{
+  "expectations": [{"url": "https://my-site.com/pricing"}]
+}`), 0644))
+
+	factory := &optionsMuffetFactory{mockMuffetFactory: mockMuffetFactory{&mockMuffetExecutor{result: jsonReportExpectations}}}
+	cf := newCommandFilter(&bytes.Buffer{}, &bytes.Buffer{}, false, factory)
+
+	// the short option of muffet already requests the successful links
+	_, err := cf.runWithError([]string{"-i", ignoreListFile, "--muffet-arg=-v", "https://my-site.com/"})
+	assert.Nil(t, err)
+	assert.Contains(t, factory.options.arguments, "-v")
+	assert.NotContains(t, factory.options.arguments, "--verbose")
+}
+
+func TestCommandFilterExpectationsReportWithoutSuccessLinks(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`{
+  "expectations": [{"url": "https://my-site.com/pricing"}]
+}`), 0644))
+
+	cf := newCommandFilter(&bytes.Buffer{}, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	_, err := cf.runWithError([]string{"-i", ignoreListFile, "-j", "testdata/reportErrorsOnly.json"})
+	assert.EqualError(t, err, "testdata/reportErrorsOnly.json has no successful links, which are needed to check the "+
+		"expectations: the report was not produced with muffet --verbose")
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// explainArguments holds the options of the explain subcommand. The ignores, site url and normalization options are
+// the same as for the check, so the link is matched like in the report.
+type explainArguments struct {
+	arguments
+	LinkUrl   string `long:"url" description:"Url of the broken link" required:"true"`
+	LinkError string `long:"error" description:"Error of the broken link, as reported by muffet"`
+	Page      string `long:"page" description:"Page on which the broken link appears"`
+}
+
+const explainUsage = "explain [options] --url <link> --error <message> [--page <page>] [<url of website to check>]"
+
+// matchPart is the result of matching one field of a rule against the broken link.
+type matchPart struct {
+	field   string
+	pattern string
+	isMatch bool
+	// how describes how the field matched or failed, e.g. "equal" or "regex"
+	how string
+}
+
+func (part *matchPart) String() string {
+	result := "no match"
+	if part.isMatch {
+		result = "match"
+	}
+	pattern := part.pattern
+	if pattern == "" {
+		// an empty pattern matches anything
+		pattern = `""`
+	}
+	return fmt.Sprintf("  %-12s %-8s (%s) %s", part.field+":", result, part.how, pattern)
+}
+
+// explainPattern matches the value like isPatternMatch, and describes how.
+func explainPattern(field, pattern, value string) matchPart {
+	part := matchPart{field: field, pattern: pattern}
+	if value == pattern {
+		part.isMatch, part.how = true, "equal"
+		return part
+	}
+	r := compilePattern(pattern)
+	if r == nil {
+		part.how = "invalid regex"
+		return part
+	}
+	part.isMatch, part.how = r.MatchString(value), "regex"
+	return part
+}
+
+// explain matches every field of the rule against the broken link, without stopping at the first mismatch.
+// The rule matches when all parts match, which is the same result as isMatch.
+func (rule *IgnoreRule) explain(page string, errorLink UrlErrorLink) (parts []matchPart) {
+	parts = append(parts,
+		explainPattern("url", rule.Url, errorLink.Url),
+		explainPattern("error", rule.Error, errorLink.Error))
+	if rule.Page != "" {
+		parts = append(parts, explainPattern("page", rule.Page, page))
+	}
+	if rule.PageExclude != "" {
+		// the rule applies on pages not matching PageExclude
+		part := explainPattern("pageExclude", rule.PageExclude, page)
+		if part.isMatch {
+			part.isMatch, part.how = false, "excluded, "+part.how
+		} else {
+			part.isMatch, part.how = true, "not excluded"
+		}
+		parts = append(parts, part)
+	}
+
+	category, status := classifyError(errorLink.Error)
+	if rule.Status != "" {
+		parts = append(parts, matchPart{"status", rule.Status, isStatusMatch(rule.Status, status), fmt.Sprintf("status %d", status)})
+	}
+	if rule.Category != "" {
+		parts = append(parts, matchPart{"category", rule.Category, errorCategory(rule.Category) == category, "category " + string(category)})
+	}
+	if rule.When != "" {
+		part := matchPart{field: "when", pattern: rule.When, how: "expression"}
+		if expr, err := compileWhen(rule.When); err != nil {
+			part.how = "invalid expression"
+		} else {
+			part.isMatch = expr.isMatch(page, errorLink)
+		}
+		parts = append(parts, part)
+	}
+	if rule.Expires != "" {
+		how := "not expired"
+		if rule.isExpired() {
+			how = "expired"
+		}
+		parts = append(parts, matchPart{"expires", rule.Expires, !rule.isExpired(), how})
+	}
+	return
+}
+
+func isAllMatch(parts []matchPart) bool {
+	for _, part := range parts {
+		if !part.isMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// explainLink describes how each rule matches the broken link, and which rule ignores it. Like filter, the first
+// matching rule decides, and a rule above its threshold in the report does not ignore the link. exceeded holds these
+// rules, or is nil without a report, then thresholds are not accounted for.
+func explainLink(rules []IgnoreRule, exceeded map[*IgnoreRule]bool, page string, errorLink UrlErrorLink) string {
+	category, _ := classifyError(errorLink.Error)
+	lines := []string{
+		"link:  " + errorLink.Url,
+		fmt.Sprintf("error: %s (%s)", errorLink.Error, category),
+		"page:  " + page,
+	}
+
+	var decision *IgnoreRule
+	for i := range rules {
+		rule := &rules[i]
+		parts := rule.explain(page, errorLink)
+		result := "no match"
+		if isAllMatch(parts) {
+			result = "match"
+			if exceeded[rule] {
+				result = "match, but above its threshold in the report"
+			}
+			if decision == nil {
+				decision = rule
+			}
+		}
+		lines = append(lines, "", fmt.Sprintf("%s: %s", rule.origin(), result))
+		for _, part := range parts {
+			lines = append(lines, part.String())
+		}
+	}
+
+	lines = append(lines, "")
+	if len(rules) == 0 {
+		lines = append(lines, "no ignore rules loaded")
+	}
+	switch {
+	case decision != nil && exceeded[decision]:
+		lines = append(lines, "decision: not ignored, because "+decision.origin()+" is above its threshold in the report")
+	case decision != nil && decision.hasThreshold() && exceeded == nil:
+		lines = append(lines, "decision: ignored by "+decision.origin()+", unless it is above its threshold, use -j to check a report")
+	case decision != nil:
+		lines = append(lines, "decision: ignored by "+decision.origin())
+	default:
+		lines = append(lines, "decision: not ignored")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runExplain shows why a broken link is, or is not, ignored by the loaded rules.
+func (c *commandFilter) runExplain(ss []string) (bool, error) {
+	args := explainArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&explainArguments{}, explainUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if len(remaining) > 1 {
+		return false, fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(&explainArguments{}, explainUsage))
+	} else if len(remaining) == 1 {
+		args.URL = remaining[0]
+	}
+
+	// without the url of the website, the site patterns are explained as written
+	ignores, err := loadIgnoresIfSiteKnown(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+	page, errorLink := args.Page, UrlErrorLink{Url: args.LinkUrl, Error: args.LinkError}
+	if options := args.getNormalizeOptions(); options != nil {
+		// the urls of the report are normalized before matching
+		page, errorLink.Url = options.normalizeUrl(page), options.normalizeUrl(errorLink.Url)
+	}
+	var exceeded map[*IgnoreRule]bool
+	if args.MuffetJson != "" {
+		// thresholds count the links of the whole report
+		report, err := c.check(&args.arguments, nil)
+		if err != nil {
+			return false, err
+		}
+		exceeded, _ = report.getExceededRules(ignores.Rules)
+	}
+	c.print(explainLink(ignores.Rules, exceeded, page, errorLink))
+	return true, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainPattern(t *testing.T) {
+	assert.Equal(t, matchPart{"url", "https://a.com/", true, "equal"}, explainPattern("url", "https://a.com/", "https://a.com/"))
+	assert.Equal(t, matchPart{"url", "^https://a\\.com/", true, "regex"}, explainPattern("url", "^https://a\\.com/", "https://a.com/x"))
+	assert.Equal(t, matchPart{"url", "https://b.com/", false, "regex"}, explainPattern("url", "https://b.com/", "https://a.com/"))
+	assert.Equal(t, matchPart{"url", "https://a.com/(", false, "invalid regex"}, explainPattern("url", "https://a.com/(", "https://a.com/"))
+}
+
+func TestIgnoreRuleExplainAgreesWithIsMatch(t *testing.T) {
+	origNow := now
+	defer func() {
+		now = origNow
+	}()
+	now = func() time.Time { return time.Date(2026, 3, 15, 10, 0, 0, 0, time.Local) }
+
+	page := "https://my-site.com/docs/"
+	errorLink := UrlErrorLink{Url: "https://partner.com/api", Error: "503"}
+	rules := []IgnoreRule{
+		{Url: "https://partner.com/api", Error: "503"},
+		{Url: "https://partner.com/.*", Status: "5xx"},
+		{Url: "https://partner.com/.*", Category: "timeout"},
+		{Url: "https://partner.com/.*", Page: "/blog/"},
+		{Url: "https://partner.com/.*", PageExclude: "/docs/"},
+		{Url: "https://partner.com/.*", PageExclude: "/blog/"},
+		{Url: "https://partner.com/.*", Expires: "2026-03-01"},
+		{Url: "https://partner.com/.*", Expires: "2026-04-01"},
+		{Url: "https://partner.com/.*", When: "external && status >= 500"},
+		{Url: "https://partner.com/.*", When: `startsWith(page.path, "/blog")`},
+		{Url: "https://other.com/", Error: "503"},
+	}
+	for _, rule := range rules {
+		assert.Equal(t, rule.isMatch(page, errorLink), isAllMatch(rule.explain(page, errorLink)), "%+v", rule)
+	}
+}
+
+func TestExplainLink(t *testing.T) {
+	rules := []IgnoreRule{
+		{Url: "https://partner.com/", Error: "404", source: "--ignore", adHoc: true},
+		{Url: "https://partner.com/.*", Status: "5xx", source: "ignores.json"},
+		{Url: "https://partner.com/api", source: "ignores.json", index: 1},
+	}
+	explanation := explainLink(rules, nil, "https://my-site.com/", UrlErrorLink{Url: "https://partner.com/api", Error: "503"})
+	assert.Contains(t, explanation, `error: 503 (http-status)
+page:  https://my-site.com/
+
+--ignore rule 0 (ad hoc): no match
+  url:         match    (regex) https://partner.com/
+  error:       no match (regex) 404
+
+ignores.json rule 0: match
+  url:         match    (regex) https://partner.com/.*
+  error:       match    (regex) ""
+  status:      match    (status 503) 5xx
+
+ignores.json rule 1: match
+  url:         match    (equal) https://partner.com/api
+  error:       match    (regex) ""
+
+decision: ignored by ignores.json rule 0`)
+
+	explanation = explainLink(nil, nil, "", UrlErrorLink{Url: "https://partner.com/api", Error: "503"})
+	assert.Contains(t, explanation, "no ignore rules loaded\ndecision: not ignored")
+}
+
+func TestExplainLinkThreshold(t *testing.T) {
+	rules := []IgnoreRule{
+		{Url: "https://partner.com/.*", Error: "404", MaxOccurrences: 1, source: "ignores.json"},
+		{Url: "https://partner.com/api", source: "ignores.json", index: 1},
+	}
+	errorLink := UrlErrorLink{Url: "https://partner.com/api", Error: "404"}
+
+	// without a report, thresholds are not accounted for
+	explanation := explainLink(rules, nil, "https://my-site.com/", errorLink)
+	assert.Contains(t, explanation, "decision: ignored by ignores.json rule 0, unless it is above its threshold, use -j to check a report")
+
+	explanation = explainLink(rules, map[*IgnoreRule]bool{&rules[0]: true}, "https://my-site.com/", errorLink)
+	assert.Contains(t, explanation, "ignores.json rule 0: match, but above its threshold in the report\n")
+	assert.Contains(t, explanation, "decision: not ignored, because ignores.json rule 0 is above its threshold in the report")
+
+	explanation = explainLink(rules, map[*IgnoreRule]bool{}, "https://my-site.com/", errorLink)
+	assert.True(t, strings.HasSuffix(explanation, "decision: ignored by ignores.json rule 0"))
+}
+
+func TestRunExplainThreshold(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	dir := t.TempDir()
+	ignoreListFile := filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[{"url": "^https://partner\\.com/", "error": "404", "maxOccurrences": 1}]`), 0644))
+	reportFile := filepath.Join(dir, "report.json")
+	assert.Nil(t, os.WriteFile(reportFile, []byte(`[{"url": "https://my-site.com/", "links": [
+  {"url": "https://partner.com/a", "error": "404"},
+  {"url": "https://partner.com/b", "error": "404"}
+]}]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"explain", "-i", ignoreListFile, "-j", reportFile,
+		"--url", "https://partner.com/a", "--error", "404", "--page", "https://my-site.com/"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "decision: not ignored, because "+ignoreListFile+" rule 0 is above its threshold in the report")
+}
+
+func TestExplain(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"explain", "-i", "testdata/ignores.yaml", "--url", "https://www.apache.org/licenses/#apply",
+		"--error", "id #apply not found", "--page", "https://bhamail.github.io/picapsule/archive/"})
+	assert.True(t, ok)
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "  pageExclude: no match (excluded, regex) /archive/")
+	assert.Contains(t, stdout.String(), "decision: not ignored")
+
+	stdout.Reset()
+	ok = cf.Run([]string{"explain", "-i", "testdata/ignores.yaml"})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "the required flag `--url' was not specified")
+
+	ok = cf.Run([]string{"explain", "--help"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "--error")
+}
+
+func TestRunExplainLikeCheck(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+
+	// the site-relative rule is resolved against the website, and the link is normalized like in the report
+	ok, err := cf.runWithError([]string{"explain", "--ignore", "site:/downloads/tool\\.zip$=404", "--strip-query", "utm_source",
+		"--url", "HTTP://My-Site.com:80/downloads/tool.zip?utm_source=mail", "--error", "404", "http://my-site.com/"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "link:  http://my-site.com/downloads/tool.zip\n")
+	assert.Contains(t, stdout.String(), "decision: ignored by --ignore rule 0 (ad hoc)")
+
+	// without the url of the website, the site-relative rule is explained as written
+	t.Setenv(siteUrlVariable, "")
+	stdout.Reset()
+	ok, err = cf.runWithError([]string{"explain", "--ignore", "site:/downloads/tool\\.zip$=404",
+		"--url", "https://other.com/downloads/tool.zip", "--error", "404"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "  url:         no match (regex) site:/downloads/tool\\.zip$\n")
+
+	_, err = cf.runWithError([]string{"explain", "--url", "https://a.com/", "https://my-site.com/", "https://other.com/"})
+	assert.ErrorContains(t, err, "invalid number of arguments")
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v3"
+)
+
+type fmtArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"Ignores file to format. Can be repeated. Defaults to the local default ignores files"`
+	Check       bool     `long:"check" description:"Only check the formatting, and fail if a file would be changed"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+const fmtUsage = "fmt [options] [ignores files]"
+
+// ruleKeys and ignoreFileKeys are the canonical order of the keys in rules and in ignores files. Unknown keys follow.
+var ruleKeys = []string{"id", "url", "error", "page", "pageExclude", "status", "category", "when", "reason", "added", "expires", "maxOccurrences", "maxPages"}
+var ignoreFileKeys = []string{"$schema", "definitions", "include", "use", "rules", "expectations"}
+
+// sortMappingKeys orders the key-value pairs of a mapping node by the canonical keys.
+func sortMappingKeys(mapping *yaml.Node, keys []string) {
+	type pair struct{ key, value *yaml.Node }
+	var pairs []pair
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		pairs = append(pairs, pair{mapping.Content[i], mapping.Content[i+1]})
+	}
+	rank := func(p pair) int {
+		if i := slices.Index(keys, p.key.Value); i >= 0 {
+			return i
+		}
+		return len(keys)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i]) < rank(pairs[j])
+	})
+	mapping.Content = mapping.Content[:0]
+	for _, p := range pairs {
+		mapping.Content = append(mapping.Content, p.key, p.value)
+	}
+}
+
+// getRuleSortKey sorts rules by host, then by their fields.
+func getRuleSortKey(rule IgnoreRule) string {
+	host := ""
+	if match := hostPatternPart.FindStringSubmatch(rule.Url); match != nil {
+		host = strings.ToLower(strings.ReplaceAll(match[1], `\.`, "."))
+	}
+	return strings.Join([]string{host, rule.Url, rule.Error, rule.Page, rule.PageExclude, rule.Status, rule.Category}, "\x00")
+}
+
+// ruleSection is a list of rules, which starts with a comment, e.g. "# partner sites". Rules are sorted within their
+// section, so the sections stay in their order. Rules with a threshold stay in place, and the rules before them stay
+// before them, as a threshold only counts the links not matched by an earlier rule.
+type ruleSection struct {
+	comment string
+	nodes   []*yaml.Node
+	rules   []IgnoreRule
+}
+
+// formatRules sorts the rules within their comment sections, and removes exact duplicates.
+func formatRules(ignoreListFile string, rulesNode *yaml.Node) error {
+	var sections []*ruleSection
+	seen := map[IgnoreRule]bool{}
+	for i, ruleNode := range rulesNode.Content {
+		var rule IgnoreRule
+		if err := ruleNode.Decode(&rule); err != nil {
+			return newIgnoreFileError(ignoreListFile, ruleNode, "rule %d: %v", i, yamlErrorMessage(err))
+		}
+		if len(sections) == 0 || ruleNode.HeadComment != "" {
+			sections = append(sections, &ruleSection{comment: ruleNode.HeadComment})
+			ruleNode.HeadComment = ""
+		}
+		if seen[rule] {
+			continue
+		}
+		seen[rule] = true
+		if ruleNode.Kind == yaml.MappingNode {
+			sortMappingKeys(ruleNode, ruleKeys)
+		}
+		section := sections[len(sections)-1]
+		section.nodes = append(section.nodes, ruleNode)
+		section.rules = append(section.rules, rule)
+	}
+
+	rulesNode.Content = nil
+	comment := ""
+	for _, section := range sections {
+		comment = strings.TrimSpace(strings.Join([]string{comment, section.comment}, "\n"))
+		if len(section.nodes) == 0 {
+			// the comment of a section with only duplicates goes to the next section
+			continue
+		}
+		// the rules are only sorted between the rules with a threshold
+		indexes := make([]int, len(section.nodes))
+		groups := make([]int, len(section.nodes))
+		group := 0
+		for i := range indexes {
+			indexes[i] = i
+			if section.rules[i].hasThreshold() {
+				groups[i] = group + 1
+				group += 2
+			} else {
+				groups[i] = group
+			}
+		}
+		sort.SliceStable(indexes, func(i, j int) bool {
+			if groups[indexes[i]] != groups[indexes[j]] {
+				return groups[indexes[i]] < groups[indexes[j]]
+			}
+			return getRuleSortKey(section.rules[indexes[i]]) < getRuleSortKey(section.rules[indexes[j]])
+		})
+		for i, index := range indexes {
+			if i == 0 {
+				section.nodes[index].HeadComment = comment
+				comment = ""
+			}
+			rulesNode.Content = append(rulesNode.Content, section.nodes[index])
+		}
+	}
+	if comment != "" {
+		rulesNode.FootComment = strings.TrimSpace(strings.Join([]string{comment, rulesNode.FootComment}, "\n"))
+	}
+	return nil
+}
+
+// formatIgnoreFile returns the canonical content of an ignores file: rules sorted and without duplicates, keys in
+// canonical order, and a stable indent. Comments are kept.
+func formatIgnoreFile(ignoreListFile string, content []byte) ([]byte, error) {
+	doc, err := parseIgnoreDocument(ignoreListFile, content)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = decodeIgnoreFile(ignoreListFile, doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return content, nil
+	}
+
+	root := doc
+	if doc.Kind == yaml.DocumentNode {
+		root = doc.Content[0]
+	}
+	if root.Kind == yaml.MappingNode {
+		sortMappingKeys(root, ignoreFileKeys)
+	}
+	rulesNode, err := getRulesNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err = formatRules(ignoreListFile, rulesNode); err != nil {
+		return nil, err
+	}
+	return marshalIgnoreDocument(ignoreListFile, doc)
+}
+
+// runFmt rewrites ignores files in their canonical format. With --check, it only reports the files which are not
+// formatted, and fails if there are any, e.g. in CI.
+func (c *commandFilter) runFmt(ss []string) (bool, error) {
+	args := fmtArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&fmtArguments{}, fmtUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	ignoreListFiles := append(args.IgnoresJson, remaining...)
+	if len(ignoreListFiles) == 0 {
+		for _, ignoreListFile := range findDefaultIgnoresFiles() {
+			if !isRemoteIgnoreSource(ignoreListFile) {
+				ignoreListFiles = append(ignoreListFiles, ignoreListFile)
+			}
+		}
+	}
+
+	ok := true
+	for _, ignoreListFile := range ignoreListFiles {
+		if isRemoteIgnoreSource(ignoreListFile) {
+			return false, fmt.Errorf("cannot change remote ignores file: %s", ignoreListFile)
+		}
+		content, err := os.ReadFile(ignoreListFile)
+		if err != nil {
+			return false, err
+		}
+		formatted, err := formatIgnoreFile(ignoreListFile, content)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+		if args.Check {
+			c.print("not formatted: ", ignoreListFile)
+			ok = false
+			continue
+		}
+		if err = os.WriteFile(ignoreListFile, formatted, 0644); err != nil {
+			return false, err
+		}
+		c.print("formatted: ", ignoreListFile)
+	}
+	return ok, nil
+}
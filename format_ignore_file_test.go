@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unformattedJsonc = `{"rules": [
+// partner sites
+{"error": "404", "url": "https://z.com/"},
+    {"url": "https://a.com/b", "error": "503", "reason": "down"},
+{"url": "https://a.com/a", "error": "404"},  // first
+// duplicates only
+{"url": "https://z.com/", "error": "404"},
+// our own sites
+{"url": "https://my-site.com/", "category": "timeout",},
+], "include": ["shared.json"]}
+`
+
+const formattedJsonc = `{
+  "include": [
+    "shared.json"
+  ],
+  "rules": [
+    // partner sites
+    {
+      "url": "https://a.com/a",
+      "error": "404"
+    }, // first
+    {
+      "url": "https://a.com/b",
+      "error": "503",
+      "reason": "down"
+    },
+    {
+      "url": "https://z.com/",
+      "error": "404"
+    },
+    // duplicates only
+    // our own sites
+    {
+      "url": "https://my-site.com/",
+      "category": "timeout"
+    }
+  ]
+}
+`
+
+func TestFormatIgnoreFileJsonc(t *testing.T) {
+	formatted, err := formatIgnoreFile("ignores.json", []byte(unformattedJsonc))
+	assert.Nil(t, err)
+	assert.Equal(t, formattedJsonc, string(formatted))
+
+	// formatting is stable
+	formattedAgain, err := formatIgnoreFile("ignores.json", formatted)
+	assert.Nil(t, err)
+	assert.Equal(t, formattedJsonc, string(formattedAgain))
+}
+
+func TestFormatIgnoreFileJsoncKeepsBlockComments(t *testing.T) {
+	formatted, err := formatIgnoreFile("ignores.jsonc", []byte(`/* shared
+   rules */
+[
+  /* partner sites */
+  {"url": "https://z.com/" /* z */, "error": "404"},
+  {"error" /* e */: /* 503 */ "503", "url": "https://a.com/"}
+]
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, `/* shared
+   rules */
+[
+  /* partner sites */
+  {
+    "url": "https://a.com/",
+    "error" /* e */: /* 503 */ "503"
+  },
+  {
+    "url": "https://z.com/" /* z */,
+    "error": "404"
+  }
+]
+`, string(formatted))
+}
+
+func TestFormatIgnoreFileYamlKeepsAllFields(t *testing.T) {
+	content := `# header
+- expires: "2026-12-31"
+  added: "2026-01-01"
+  reason: partner
+  category: timeout
+  status: 5xx
+  pageExclude: /archive/
+  page: /docs/
+  error: timeout
+  url: ^https://b\.com/
+- url: https://a.com/
+  error: "404"
+`
+	formatted, err := formatIgnoreFile("ignores.yaml", []byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, `# header
+- url: https://a.com/
+  error: "404"
+- url: ^https://b\.com/
+  error: timeout
+  page: /docs/
+  pageExclude: /archive/
+  status: 5xx
+  category: timeout
+  reason: partner
+  added: "2026-01-01"
+  expires: "2026-12-31"
+`, string(formatted))
+
+	before := newIgnoreLoader(false)
+	after := newIgnoreLoader(false)
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "before.yaml"), []byte(content), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "after.yaml"), formatted, 0644))
+	assert.Nil(t, before.load(filepath.Join(dir, "before.yaml")))
+	assert.Nil(t, after.load(filepath.Join(dir, "after.yaml")))
+	assert.Equal(t, before.rules[0].Expires, after.rules[1].Expires)
+	assert.Equal(t, before.rules[0].PageExclude, after.rules[1].PageExclude)
+	assert.Equal(t, before.rules[0].Category, after.rules[1].Category)
+}
+
+func TestFormatIgnoreFileKeepsThresholds(t *testing.T) {
+	content := []byte(`[
+  {"url": "^https://github\\.com/foo", "error": "404"},
+  {"url": "^https://github\\.com/", "maxOccurrences": 1},
+  {"url": "^https://a\\.com/", "error": "404"}
+]`)
+	report, err := (&parseResponse{`[{"url": "https://my-site.com/", "links": [
+  {"url": "https://github.com/foo/a", "error": "404"},
+  {"url": "https://github.com/foo/b", "error": "404"},
+  {"url": "https://github.com/bar", "error": "404"},
+  {"url": "https://a.com/", "error": "404"}
+]}]`}).loadReport(&arguments{})
+	assert.Nil(t, err)
+	filter := func(ignoreListFile string, content []byte) Report {
+		doc, err := parseIgnoreDocument(ignoreListFile, content)
+		assert.Nil(t, err)
+		file, err := decodeIgnoreFile(ignoreListFile, doc)
+		assert.Nil(t, err)
+		filtered, err := report.filter(file.Rules, false)
+		assert.Nil(t, err)
+		return filtered
+	}
+
+	formatted, err := formatIgnoreFile("ignores.json", content)
+	assert.Nil(t, err)
+	// sorted, the threshold rule would come first, and count the links of the rule before it
+	assert.Equal(t, []string{"^https://github\\.com/foo", "^https://github\\.com/", "^https://a\\.com/"},
+		getRuleUrls(t, formatted))
+	filtered := filter("ignores.json", formatted)
+	assert.Equal(t, filter("ignores.json", content), filtered)
+	assert.Empty(t, filtered.errorLinks())
+}
+
+func getRuleUrls(t *testing.T, content []byte) (urls []string) {
+	doc, err := parseIgnoreDocument("ignores.json", content)
+	assert.Nil(t, err)
+	file, err := decodeIgnoreFile("ignores.json", doc)
+	assert.Nil(t, err)
+	for _, rule := range file.Rules {
+		urls = append(urls, rule.Url)
+	}
+	return
+}
+
+func TestFormatIgnoreFileInvalid(t *testing.T) {
+	_, err := formatIgnoreFile("ignores.json", []byte(`{"rules": {}}`))
+	assert.EqualError(t, err, "ignores.json:1:11: expected a list of rules, or an object with rules")
+}
+
+func TestFmt(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(unformattedJsonc), 0644))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"fmt", "--check", ignoreListFile})
+	assert.False(t, ok)
+	assert.Equal(t, "not formatted: "+ignoreListFile+"\n", stdout.String())
+
+	stdout.Reset()
+	ok = cf.Run([]string{"fmt", "-i", ignoreListFile})
+	assert.True(t, ok)
+	assert.Equal(t, "formatted: "+ignoreListFile+"\n", stdout.String())
+	content, err := os.ReadFile(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, formattedJsonc, string(content))
+
+	stdout.Reset()
+	ok = cf.Run([]string{"fmt", "--check", ignoreListFile})
+	assert.True(t, ok)
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	ok = cf.Run([]string{"fmt", "https://example.com/ignores.json"})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "cannot change remote ignores file: https://example.com/ignores.json")
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orgIgnoresEnvVar names an org-wide ignores file or url, shared by all projects.
+const orgIgnoresEnvVar = "MUFFET_FILTER_ORG_IGNORES"
+
+// ignoreFile is the content of an ignores file. This is either a list of rules, or an object holding the
+// rules, expectations, the rule packs to use and a list of other ignores files to include, e.g. {"include": ["../shared.json"], "rules": [...]}
+type ignoreFile struct {
+	// Schema is the url of the json schema of ignores files, used by editors
+	Schema string `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	// Definitions are named patterns, which are inserted into the patterns of rules and expectations as {{name}}
+	Definitions map[string]string `json:"definitions,omitempty" yaml:"definitions,omitempty"`
+	Include     []string          `json:"include,omitempty" yaml:"include,omitempty"`
+	// Use names the built-in rule packs to add, e.g. "social-media" or "social-media@1"
+	Use          []string      `json:"use,omitempty" yaml:"use,omitempty"`
+	Rules        []IgnoreRule  `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Expectations []Expectation `json:"expectations,omitempty" yaml:"expectations,omitempty"`
+}
+
+// ignoreFileError is an error at a line and column of an ignores file.
+type ignoreFileError struct {
+	file         string
+	line, column int
+	msg          string
+}
+
+func (e *ignoreFileError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.file, e.line, e.column, e.msg)
+}
+
+func newIgnoreFileError(file string, node *yaml.Node, format string, a ...any) error {
+	return &ignoreFileError{file, node.Line, node.Column, fmt.Sprintf(format, a...)}
+}
+
+const (
+	formatJsonc = "jsonc"
+	formatYaml  = "yaml"
+)
+
+// getIgnoreFileFormat detects the format of an ignores file by its extension. Json files may contain comments.
+func getIgnoreFileFormat(ignoreListFile string) string {
+	switch strings.ToLower(filepath.Ext(getIgnoreSourcePath(ignoreListFile))) {
+	case ".yaml", ".yml":
+		return formatYaml
+	default:
+		return formatJsonc
+	}
+}
+
+var yamlSyntaxErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseIgnoreDocument parses the content of an ignores file into a yaml node tree, including its comments.
+func parseIgnoreDocument(ignoreListFile string, data []byte) (*yaml.Node, error) {
+	if getIgnoreFileFormat(ignoreListFile) == formatYaml {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			// yaml syntax errors look like "yaml: line 3: did not find expected key", without a column
+			if match := yamlSyntaxErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				return nil, fmt.Errorf("%s:%s: %s", ignoreListFile, match[1], match[2])
+			}
+			return nil, fmt.Errorf("%s: %w", ignoreListFile, err)
+		}
+		return &doc, nil
+	}
+
+	doc, err := parseJsonc(data)
+	if err != nil {
+		if syntaxErr, ok := err.(*jsoncSyntaxError); ok {
+			return nil, &ignoreFileError{ignoreListFile, syntaxErr.line, syntaxErr.column, syntaxErr.msg}
+		}
+		return nil, err
+	}
+	return doc, nil
+}
+
+// marshalIgnoreDocument writes the yaml node tree of an ignores file in the format of the file, keeping its comments.
+func marshalIgnoreDocument(ignoreListFile string, doc *yaml.Node) ([]byte, error) {
+	if getIgnoreFileFormat(ignoreListFile) == formatYaml {
+		b := &bytes.Buffer{}
+		e := yaml.NewEncoder(b)
+		e.SetIndent(2)
+		if err := e.Encode(doc); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), e.Close()
+	}
+	return marshalJsonc(doc), nil
+}
+
+// decodeIgnoreFile decodes the node tree of an ignores file. Errors report the line and column of the invalid entry.
+func decodeIgnoreFile(ignoreListFile string, doc *yaml.Node) (file ignoreFile, err error) {
+	if doc.Kind == 0 {
+		// empty yaml document
+		return
+	}
+	if doc.Kind == yaml.DocumentNode {
+		doc = doc.Content[0]
+	}
+
+	rulesNode := doc
+	if doc.Kind == yaml.MappingNode {
+		rulesNode = nil
+		if err = checkKnownFields(ignoreListFile, doc, ignoreFileFields, ""); err != nil {
+			return
+		}
+		for i := 0; i < len(doc.Content); i += 2 {
+			key, value := doc.Content[i], doc.Content[i+1]
+			switch key.Value {
+			case "definitions":
+				if err = value.Decode(&file.Definitions); err != nil {
+					return file, newIgnoreFileError(ignoreListFile, value, "invalid definitions: %v", yamlErrorMessage(err))
+				}
+			case "include":
+				if err = value.Decode(&file.Include); err != nil {
+					return file, newIgnoreFileError(ignoreListFile, value, "invalid include: %v", yamlErrorMessage(err))
+				}
+			case "use":
+				if err = value.Decode(&file.Use); err != nil {
+					return file, newIgnoreFileError(ignoreListFile, value, "invalid use: %v", yamlErrorMessage(err))
+				}
+			case "rules":
+				rulesNode = value
+			case "expectations":
+				if file.Expectations, err = decodeExpectations(ignoreListFile, value); err != nil {
+					return
+				}
+			}
+		}
+		if rulesNode == nil {
+			return
+		}
+	}
+	if rulesNode.Kind != yaml.SequenceNode {
+		return file, newIgnoreFileError(ignoreListFile, rulesNode, "expected a list of rules, or an object with rules")
+	}
+
+	for i, ruleNode := range rulesNode.Content {
+		var rule IgnoreRule
+		if ruleNode.Kind != yaml.MappingNode {
+			return file, newIgnoreFileError(ignoreListFile, ruleNode, "rule %d: expected an object", i)
+		}
+		if err = checkKnownFields(ignoreListFile, ruleNode, ignoreRuleFields, fmt.Sprintf("rule %d: ", i)); err != nil {
+			return
+		}
+		if err = ruleNode.Decode(&rule); err != nil {
+			return file, newIgnoreFileError(ignoreListFile, ruleNode, "rule %d: %v", i, yamlErrorMessage(err))
+		}
+		if err = checkRulePatterns(ignoreListFile, ruleNode, i); err != nil {
+			return
+		}
+		if err = checkRuleWhen(ignoreListFile, ruleNode, i); err != nil {
+			return
+		}
+		if err = checkRuleDates(ignoreListFile, ruleNode, i); err != nil {
+			return
+		}
+		file.Rules = append(file.Rules, rule)
+	}
+	return
+}
+
+// the known fields of ignores files, rules and expectations. Unknown fields are usually typos, like "eror".
+var (
+	ignoreFileFields  = getJsonFieldNames(reflect.TypeOf(ignoreFile{}))
+	ignoreRuleFields  = getJsonFieldNames(reflect.TypeOf(IgnoreRule{}))
+	expectationFields = getJsonFieldNames(reflect.TypeOf(Expectation{}))
+)
+
+// patternFields are the rule fields, which are matched as patterns.
+var patternFields = []string{"url", "error", "page", "pageExclude"}
+
+func checkKnownFields(ignoreListFile string, mapping *yaml.Node, fields []string, prefix string) error {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		if !slices.Contains(fields, key.Value) {
+			return newIgnoreFileError(ignoreListFile, key, "%sunknown field %q, expected one of: %s", prefix, key.Value, strings.Join(fields, ", "))
+		}
+	}
+	return nil
+}
+
+// checkRulePatterns rejects rules without url, and empty patterns, which match everything.
+func checkRulePatterns(ignoreListFile string, ruleNode *yaml.Node, index int) error {
+	hasUrl := false
+	for i := 0; i < len(ruleNode.Content); i += 2 {
+		key, value := ruleNode.Content[i], ruleNode.Content[i+1]
+		if slices.Contains(patternFields, key.Value) && value.Value == "" {
+			return newIgnoreFileError(ignoreListFile, value, "rule %d: empty %s pattern matches everything, remove it or use \".*\"", index, key.Value)
+		}
+		hasUrl = hasUrl || key.Value == "url"
+	}
+	if !hasUrl {
+		return newIgnoreFileError(ignoreListFile, ruleNode, "rule %d: missing url", index)
+	}
+	return nil
+}
+
+// checkRuleWhen type-checks the when expression of a rule, so errors are reported at load time.
+func checkRuleWhen(ignoreListFile string, ruleNode *yaml.Node, index int) error {
+	for i := 0; i < len(ruleNode.Content); i += 2 {
+		key, value := ruleNode.Content[i], ruleNode.Content[i+1]
+		if key.Value != "when" {
+			continue
+		}
+		if _, err := compileWhen(value.Value); err != nil {
+			return newIgnoreFileError(ignoreListFile, value, "rule %d: invalid when expression: %v", index, err)
+		}
+	}
+	return nil
+}
+
+// checkRuleDates rejects malformed added and expires dates, as a rule with a malformed expiry date would never expire.
+func checkRuleDates(ignoreListFile string, ruleNode *yaml.Node, index int) error {
+	for i := 0; i < len(ruleNode.Content); i += 2 {
+		key, value := ruleNode.Content[i], ruleNode.Content[i+1]
+		if key.Value != "added" && key.Value != "expires" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, value.Value); err != nil {
+			return newIgnoreFileError(ignoreListFile, value, "rule %d: invalid %s date %q, expected YYYY-MM-DD", index, key.Value, value.Value)
+		}
+	}
+	return nil
+}
+
+func decodeExpectations(ignoreListFile string, node *yaml.Node) (expectations []Expectation, err error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, newIgnoreFileError(ignoreListFile, node, "expected a list of expectations")
+	}
+	for i, expectationNode := range node.Content {
+		var expectation Expectation
+		if expectationNode.Kind != yaml.MappingNode {
+			return nil, newIgnoreFileError(ignoreListFile, expectationNode, "expectation %d: expected an object", i)
+		}
+		if err = checkKnownFields(ignoreListFile, expectationNode, expectationFields, fmt.Sprintf("expectation %d: ", i)); err != nil {
+			return
+		}
+		if err = expectationNode.Decode(&expectation); err != nil {
+			return nil, newIgnoreFileError(ignoreListFile, expectationNode, "expectation %d: %v", i, yamlErrorMessage(err))
+		}
+		if expectation.Url == "" {
+			return nil, newIgnoreFileError(ignoreListFile, expectationNode, "expectation %d: missing url", i)
+		}
+		expectations = append(expectations, expectation)
+	}
+	return
+}
+
+var yamlErrorLinePrefix = regexp.MustCompile(`^line \d+: `)
+
+// yamlErrorMessage strips the line prefix from yaml decoding errors, because the caller reports the line and column.
+func yamlErrorMessage(err error) string {
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		var messages []string
+		for _, message := range typeErr.Errors {
+			messages = append(messages, yamlErrorLinePrefix.ReplaceAllString(message, ""))
+		}
+		return strings.Join(messages, ", ")
+	}
+	return err.Error()
+}
+
+// ignoreLoader merges the rules of ignores files, following includes.
+type ignoreLoader struct {
+	isVerbose    bool
+	loaded       map[string]bool
+	rules        []IgnoreRule
+	expectations []Expectation
+}
+
+func newIgnoreLoader(isVerbose bool) *ignoreLoader {
+	return &ignoreLoader{isVerbose: isVerbose, loaded: map[string]bool{}}
+}
+
+// load adds the rules of the ignores file, followed by the rules of the files it includes.
+// The ignores file can be a local file or an http(s) url. Includes are relative to the including file.
+func (l *ignoreLoader) load(ignoreListFile string) (err error) {
+	absPath := ignoreListFile
+	if !isRemoteIgnoreSource(ignoreListFile) {
+		if absPath, err = filepath.Abs(ignoreListFile); err != nil {
+			return
+		}
+	}
+	if l.loaded[absPath] {
+		// already loaded via another layer or include
+		return
+	}
+	l.loaded[absPath] = true
+
+	var ignoreListRaw []byte
+	if isRemoteIgnoreSource(ignoreListFile) {
+		ignoreListRaw, err = fetchIgnoreFile(ignoreListFile, l.isVerbose)
+	} else {
+		ignoreListRaw, err = os.ReadFile(ignoreListFile)
+	}
+	if err != nil {
+		return
+	}
+	var doc *yaml.Node
+	if doc, err = parseIgnoreDocument(ignoreListFile, ignoreListRaw); err != nil {
+		return
+	}
+	if err = expandIgnoreDocument(ignoreListFile, doc); err != nil {
+		return
+	}
+	var file ignoreFile
+	if file, err = decodeIgnoreFile(ignoreListFile, doc); err != nil {
+		return
+	}
+	if l.isVerbose {
+		fmt.Printf("loaded ignores file: %s, rules: %d\n", ignoreListFile, len(file.Rules))
+	}
+	for i, rule := range file.Rules {
+		rule.source, rule.index = ignoreListFile, i
+		l.rules = append(l.rules, rule)
+	}
+	l.expectations = append(l.expectations, file.Expectations...)
+	for _, use := range file.Use {
+		if err = l.loadPack(use); err != nil {
+			return fmt.Errorf("%s: %w", ignoreListFile, err)
+		}
+	}
+
+	for _, include := range file.Include {
+		if include, err = resolveIgnoreSource(ignoreListFile, include); err != nil {
+			return
+		}
+		if err = l.load(include); err != nil {
+			return fmt.Errorf("include from %s: %w", ignoreListFile, err)
+		}
+	}
+	return
+}
+
+// findIgnoresFile returns the default ignores file in the directory, or one with an alternate format.
+func findIgnoresFile(dir string) (ignoreListFile string, itExists bool) {
+	candidates := []string{getDefaultIgnoresFile(dir)}
+	for _, filename := range alternateIgnoresFilenames {
+		candidates = append(candidates, filepath.Join(dir, configDir, filename))
+	}
+	for _, ignoreListFile = range candidates {
+		if itExists, _ = doesFileExist(ignoreListFile); itExists {
+			return
+		}
+	}
+	return "", false
+}
+
+// findDefaultIgnoresFiles returns the existing default ignores files, in order of precedence: project files from the
+// current directory up to the git root, then the user file in the home directory, then the org-wide file.
+func findDefaultIgnoresFiles() (ignoreListFiles []string) {
+	pwd, _ := os.Getwd()
+	for _, dir := range getProjectDirs(pwd) {
+		if ignoreListFile, itExists := findIgnoresFile(dir); itExists {
+			ignoreListFiles = append(ignoreListFiles, ignoreListFile)
+		}
+	}
+	if homeDir, _ := getUserHomeDir(); homeDir != "" {
+		if ignoreListFile, itExists := findIgnoresFile(homeDir); itExists {
+			ignoreListFiles = append(ignoreListFiles, ignoreListFile)
+		}
+	}
+	if orgIgnoresFile := os.Getenv(orgIgnoresEnvVar); orgIgnoresFile != "" {
+		if itExists, _ := doesFileExist(orgIgnoresFile); itExists || isRemoteIgnoreSource(orgIgnoresFile) {
+			ignoreListFiles = append(ignoreListFiles, orgIgnoresFile)
+		}
+	}
+	return
+}
+
+// getProjectDirs returns the directory and its parents up to the git root. Outside a git repository,
+// only the directory itself is returned.
+func getProjectDirs(dir string) []string {
+	var dirs []string
+	for current := dir; ; {
+		dirs = append(dirs, current)
+		if itExists, _ := doesFileExist(filepath.Join(current, ".git")); itExists {
+			return dirs
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			// no git root found
+			return []string{dir}
+		}
+		current = parent
+	}
+}
+
+// getIgnoresFileToEdit returns the ignores file changed by subcommands: the first file given via -i, or the nearest
+// ignores file of the project, or a new default ignores file in the current directory.
+func getIgnoresFileToEdit(args *arguments) (string, error) {
+	if len(args.IgnoresJson) > 0 {
+		if isRemoteIgnoreSource(args.IgnoresJson[0]) {
+			return "", fmt.Errorf("cannot change remote ignores file: %s", args.IgnoresJson[0])
+		}
+		return args.IgnoresJson[0], nil
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range getProjectDirs(pwd) {
+		if ignoreListFile, itExists := findIgnoresFile(dir); itExists {
+			return ignoreListFile, nil
+		}
+	}
+	return getDefaultIgnoresFile(pwd), nil
+}
+
+// allowNewIgnoresFile lets the ignores file to edit not exist yet: it is loaded without rules, and created when the
+// first rule is added.
+func allowNewIgnoresFile(args *arguments, ignoreListFile string) {
+	args.newIgnoresFile = ignoreListFile
+}
+
+// updateIgnoresFile changes the node tree of an ignores file and writes it back, keeping its comments.
+// A missing ignores file is created.
+func updateIgnoresFile(ignoreListFile string, update func(doc *yaml.Node) error) error {
+	doc := &yaml.Node{}
+	content, err := os.ReadFile(ignoreListFile)
+	if err == nil {
+		if doc, err = parseIgnoreDocument(ignoreListFile, content); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err = update(doc); err != nil {
+		return err
+	}
+	if content, err = marshalIgnoreDocument(ignoreListFile, doc); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(ignoreListFile), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(ignoreListFile, content, 0644)
+}
+
+// getRulesNode returns the list of rules in the node tree of an ignores file, adding an empty list if there is none.
+func getRulesNode(doc *yaml.Node) (*yaml.Node, error) {
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.SequenceNode, Tag: "!!seq"}}
+	}
+	root := doc
+	if doc.Kind == yaml.DocumentNode {
+		root = doc.Content[0]
+	}
+
+	switch root.Kind {
+	case yaml.SequenceNode:
+		return root, nil
+	case yaml.MappingNode:
+		for i := 0; i < len(root.Content); i += 2 {
+			if root.Content[i].Value == "rules" {
+				return root.Content[i+1], nil
+			}
+		}
+		rulesNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "rules"}, rulesNode)
+		return rulesNode, nil
+	}
+	return nil, fmt.Errorf("expected a list of rules, or an object with rules")
+}
+
+// appendIgnoreRules adds rules at the end of the list of rules in the node tree of an ignores file.
+func appendIgnoreRules(doc *yaml.Node, rules []IgnoreRule) error {
+	rulesNode, err := getRulesNode(doc)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		ruleNode := &yaml.Node{}
+		if err = ruleNode.Encode(rule); err != nil {
+			return err
+		}
+		rulesNode.Content = append(rulesNode.Content, ruleNode)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestIgnoreLoaderInclude(t *testing.T) {
+	loader := newIgnoreLoader(true)
+	err := loader.load("testdata/include/project.json")
+	assert.Nil(t, err)
+	// the include cycle back to project.json is only loaded once
+	assert.Equal(t, []IgnoreRule{
+		{Url: "https://www.example.com/downloads", Error: "404", source: "testdata/include/project.json"},
+		{Url: "https://www.linkedin.com/.*", Error: "999", source: "testdata/include/shared.json"},
+	}, loader.rules)
+}
+
+func TestIgnoreLoaderIncludeMissing(t *testing.T) {
+	loader := newIgnoreLoader(false)
+	err := loader.load("testdata/include/missing.json")
+	assert.EqualError(t, err, "include from testdata/include/missing.json: open testdata/include/no-such-file.json: no such file or directory")
+}
+
+func TestIgnoreLoaderListFormat(t *testing.T) {
+	loader := newIgnoreLoader(false)
+	err := loader.load("testdata/urlErrorIgnore.json")
+	assert.Nil(t, err)
+	assert.Equal(t, IgnoreRule{Url: "https://www.raspberrypi.com/software/", Error: "403", source: "testdata/urlErrorIgnore.json"}, loader.rules[0])
+	assert.Equal(t, "testdata/urlErrorIgnore.json rule 1", loader.rules[1].origin())
+}
+
+func TestLoadIgnoreListRepeated(t *testing.T) {
+	args := arguments{IgnoresJson: []string{"testdata/include/shared.json", "testdata/urlErrorIgnore.json"}}
+	ignores, err := loadIgnoreList(&args)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://www.linkedin.com/.*", ignores[0].Url)
+	assert.Equal(t, "https://www.example.com/downloads", ignores[1].Url)
+	assert.Equal(t, "https://www.raspberrypi.com/software/", ignores[2].Url)
+}
+
+func writeIgnoresFile(t *testing.T, dir, url string) {
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, configDir), 0755))
+	content := `[{"url": "` + url + `", "error": "404"}]`
+	assert.Nil(t, os.WriteFile(getDefaultIgnoresFile(dir), []byte(content), 0644))
+}
+
+func TestFindDefaultIgnoresFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir := filepath.Join(tempDir, "project")
+	subDir := filepath.Join(projectDir, "docs", "site")
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+	assert.Nil(t, os.MkdirAll(subDir, 0755))
+	homeDir := filepath.Join(tempDir, "home")
+	orgIgnores := filepath.Join(tempDir, "org-ignores.json")
+	assert.Nil(t, os.WriteFile(orgIgnores, []byte(`[{"url": "org", "error": "404"}]`), 0644))
+
+	// files above the git root are not part of the project
+	writeIgnoresFile(t, tempDir, "outside")
+	writeIgnoresFile(t, projectDir, "project")
+	writeIgnoresFile(t, subDir, "site")
+	writeIgnoresFile(t, homeDir, "user")
+
+	t.Chdir(subDir)
+	t.Setenv("HOME", homeDir)
+	t.Setenv(orgIgnoresEnvVar, orgIgnores)
+
+	assert.Equal(t, []string{
+		getDefaultIgnoresFile(subDir),
+		getDefaultIgnoresFile(projectDir),
+		getDefaultIgnoresFile(homeDir),
+		orgIgnores,
+	}, findDefaultIgnoresFiles())
+
+	ignores, err := loadIgnoreList(&arguments{Verbose: true})
+	assert.Nil(t, err)
+	var urls []string
+	for _, ignore := range ignores {
+		urls = append(urls, ignore.Url)
+	}
+	assert.Equal(t, []string{"site", "project", "user", "org"}, urls)
+}
+
+func TestGetProjectDirsNoGitRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	assert.Equal(t, []string{tempDir}, getProjectDirs(tempDir))
+}
+
+func getExpectedCommentedRules(source string) []IgnoreRule {
+	return []IgnoreRule{
+		{Url: "https://www.raspberrypi.com/.*", Error: "403", source: source},
+		{Url: "https://www.apache.org/licenses/#apply", Error: "id #apply not found", Page: "https://bhamail.github.io/picapsule/", source: source, index: 1},
+	}
+}
+
+func TestIgnoreLoaderJsonc(t *testing.T) {
+	loader := newIgnoreLoader(false)
+	err := loader.load("testdata/ignores.jsonc")
+	assert.Nil(t, err)
+	assert.Equal(t, getExpectedCommentedRules("testdata/ignores.jsonc"), loader.rules)
+}
+
+func TestIgnoreLoaderYaml(t *testing.T) {
+	loader := newIgnoreLoader(false)
+	err := loader.load("testdata/ignores.yaml")
+	assert.Nil(t, err)
+	expected := getExpectedCommentedRules("testdata/ignores.yaml")
+	expected[1].PageExclude = "/archive/"
+	assert.Equal(t, expected, loader.rules)
+}
+
+func TestGetIgnoreFileFormat(t *testing.T) {
+	assert.Equal(t, formatJsonc, getIgnoreFileFormat("ignores.json"))
+	assert.Equal(t, formatJsonc, getIgnoreFileFormat("ignores.jsonc"))
+	assert.Equal(t, formatYaml, getIgnoreFileFormat("ignores.yaml"))
+	assert.Equal(t, formatYaml, getIgnoreFileFormat("IGNORES.YML"))
+}
+
+func TestDecodeIgnoreFileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		file, content, err string
+	}{
+		{"a.json", "[\n  {\"url\": \"a\",\n  \"error\": 404,}\n", "a.json:4:1: parsing array after value: unexpected EOF"},
+		{"a.json", "[\n  {\"url\": \"a\", \"error\": [\"404\"]}\n]", "a.json:2:3: rule 0: cannot unmarshal !!seq into string"},
+		{"a.json", "[\n  {\"url\": \"a\"},\n  \"b\"\n]", "a.json:3:3: rule 1: expected an object"},
+		{"a.json", "{\"rules\": {}}", "a.json:1:11: expected a list of rules, or an object with rules"},
+		{"a.json", "{\"include\": 1}", "a.json:1:13: invalid include: cannot unmarshal !!int `1` into []string"},
+		{"a.yaml", "rules:\n  - url: a\n    error: [404]\n", "a.yaml:2:5: rule 0: cannot unmarshal !!seq into string"},
+		{"a.yml", "rules:\n  - url: a\n   error: 404\n", "a.yml:1: did not find expected '-' indicator"},
+		{"a.json", "[\n  {\"url\": \"a\", \"expires\": \"2026-13-01\"}\n]", "a.json:2:27: rule 0: invalid expires date \"2026-13-01\", expected YYYY-MM-DD"},
+		{"a.yaml", "- url: a\n  added: 1/2/2026\n", "a.yaml:2:10: rule 0: invalid added date \"1/2/2026\", expected YYYY-MM-DD"},
+	} {
+		doc, err := parseIgnoreDocument(tc.file, []byte(tc.content))
+		if err == nil {
+			_, err = decodeIgnoreFile(tc.file, doc)
+		}
+		assert.EqualError(t, err, tc.err, tc.content)
+	}
+}
+
+func TestDecodeIgnoreFileEmptyYaml(t *testing.T) {
+	doc, err := parseIgnoreDocument("a.yaml", []byte("# nothing yet\n"))
+	assert.Nil(t, err)
+	file, err := decodeIgnoreFile("a.yaml", doc)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Rules)
+}
+
+func TestMarshalIgnoreDocumentKeepsComments(t *testing.T) {
+	for _, ignoreListFile := range []string{"testdata/ignores.jsonc", "testdata/ignores.yaml"} {
+		content, err := os.ReadFile(ignoreListFile)
+		assert.Nil(t, err)
+		doc, err := parseIgnoreDocument(ignoreListFile, content)
+		assert.Nil(t, err)
+
+		rewritten, err := marshalIgnoreDocument(ignoreListFile, doc)
+		assert.Nil(t, err)
+		assert.Contains(t, string(rewritten), "raspberrypi.com blocks non-browser user agents")
+		assert.Contains(t, string(rewritten), "forbidden")
+
+		// the rewritten file loads the same rules
+		doc, err = parseIgnoreDocument(ignoreListFile, rewritten)
+		assert.Nil(t, err)
+		file, err := decodeIgnoreFile(ignoreListFile, doc)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(file.Rules))
+	}
+}
+
+func TestFindIgnoresFileAlternateFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(tempDir, configDir), 0755))
+	_, itExists := findIgnoresFile(tempDir)
+	assert.False(t, itExists)
+
+	yamlFile := filepath.Join(tempDir, configDir, "ignores.yaml")
+	assert.Nil(t, os.WriteFile(yamlFile, []byte("- url: a\n  error: b\n"), 0644))
+	ignoreListFile, itExists := findIgnoresFile(tempDir)
+	assert.True(t, itExists)
+	assert.Equal(t, yamlFile, ignoreListFile)
+
+	// the json file takes precedence
+	writeIgnoresFile(t, tempDir, "json")
+	ignoreListFile, itExists = findIgnoresFile(tempDir)
+	assert.True(t, itExists)
+	assert.Equal(t, getDefaultIgnoresFile(tempDir), ignoreListFile)
+}
+
+func TestAppendIgnoreRules(t *testing.T) {
+	for _, tc := range []struct {
+		content, expected string
+	}{
+		{"", "[\n  {\n    \"url\": \"a\",\n    \"error\": \"b\"\n  }\n]\n"},
+		{"{\"include\": [\"x.json\"]}", "{\n  \"include\": [\n    \"x.json\"\n  ],\n  \"rules\": [\n    {\n      \"url\": \"a\",\n      \"error\": \"b\"\n    }\n  ]\n}\n"},
+		{"// keep me\n{\"rules\": []}", "// keep me\n{\n  \"rules\": [\n    {\n      \"url\": \"a\",\n      \"error\": \"b\"\n    }\n  ]\n}\n"},
+	} {
+		ignoreListFile := filepath.Join(t.TempDir(), configDir, "ignores.json")
+		if tc.content != "" {
+			assert.Nil(t, os.MkdirAll(filepath.Dir(ignoreListFile), 0755))
+			assert.Nil(t, os.WriteFile(ignoreListFile, []byte(tc.content), 0644))
+		}
+		err := updateIgnoresFile(ignoreListFile, func(doc *yaml.Node) error {
+			return appendIgnoreRules(doc, []IgnoreRule{{Url: "a", Error: "b"}})
+		})
+		assert.Nil(t, err)
+		content, err := os.ReadFile(ignoreListFile)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, string(content))
+	}
+}
+
+func TestAppendIgnoreRulesInvalid(t *testing.T) {
+	doc, err := parseIgnoreDocument("a.json", []byte(`"notJson"`))
+	assert.Nil(t, err)
+	assert.EqualError(t, appendIgnoreRules(doc, nil), "expected a list of rules, or an object with rules")
+}
+
+func TestGetIgnoresFileToEdit(t *testing.T) {
+	ignoreListFile, err := getIgnoresFileToEdit(&arguments{IgnoresJson: []string{"a.json", "b.json"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "a.json", ignoreListFile)
+
+	tempDir := t.TempDir()
+	subDir := filepath.Join(tempDir, "docs")
+	assert.Nil(t, os.MkdirAll(filepath.Join(tempDir, ".git"), 0755))
+	assert.Nil(t, os.MkdirAll(subDir, 0755))
+	t.Chdir(subDir)
+
+	// without an ignores file, a new one is created in the current directory
+	ignoreListFile, err = getIgnoresFileToEdit(&arguments{})
+	assert.Nil(t, err)
+	assert.Equal(t, getDefaultIgnoresFile(subDir), ignoreListFile)
+
+	// the nearest existing ignores file of the project is used
+	writeIgnoresFile(t, tempDir, "project")
+	ignoreListFile, err = getIgnoresFileToEdit(&arguments{})
+	assert.Nil(t, err)
+	assert.Equal(t, getDefaultIgnoresFile(tempDir), ignoreListFile)
+}
+
+func TestDecodeIgnoreFileStrict(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected string
+	}{
+		{`[{"url": "https://a.com/", "eror": "404"}]`, `ignores.json:1:28: rule 0: unknown field "eror", expected one of: id, url, error, page, pageExclude, status, category, when, reason, added, expires, maxOccurrences, maxPages`},
+		{`[{"url": "https://a.com/", "error": "404"}, {"url": "https://b.com/", "error": ""}]`, `ignores.json:1:80: rule 1: empty error pattern matches everything, remove it or use ".*"`},
+		{`[{"url": "", "error": "404"}]`, `ignores.json:1:10: rule 0: empty url pattern matches everything, remove it or use ".*"`},
+		{`[{"error": "404"}]`, `ignores.json:1:2: rule 0: missing url`},
+		{`{"rule": []}`, `ignores.json:1:2: unknown field "rule", expected one of: $schema, definitions, include, use, rules, expectations`},
+		{`{"expectations": [{"url": "https://a.com/", "state": "200"}]}`, `ignores.json:1:45: expectation 0: unknown field "state", expected one of: url, page, status, reason`},
+	}
+	for _, tc := range testCases {
+		doc, err := parseIgnoreDocument("ignores.json", []byte(tc.content))
+		assert.Nil(t, err)
+		_, err = decodeIgnoreFile("ignores.json", doc)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// IgnoreRule is an entry of the ignores file. Url and Error are matched against the broken link,
+// Page and PageExclude (both optional) are matched against the page on which the link appears.
+// Status (e.g. "404" or "5xx") and Category (e.g. "timeout") are matched against the parsed error.
+// When is an expression over the link, the page and the parsed error, see when_expression.go.
+type IgnoreRule struct {
+	// Id (optional) identifies the rule, e.g. to remove it with "ignores remove"
+	Id          string `json:"id,omitempty" yaml:"id,omitempty"`
+	Url         string `json:"url" yaml:"url"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+	Page        string `json:"page,omitempty" yaml:"page,omitempty"`
+	PageExclude string `json:"pageExclude,omitempty" yaml:"pageExclude,omitempty"`
+	Status      string `json:"status,omitempty" yaml:"status,omitempty"`
+	Category    string `json:"category,omitempty" yaml:"category,omitempty"`
+	// When (optional) is an expression, which must be true for the broken link, e.g. `status == 403 && external`
+	When   string `json:"when,omitempty" yaml:"when,omitempty"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Added and Expires are dates (YYYY-MM-DD). An expired rule no longer ignores anything.
+	Added   string `json:"added,omitempty" yaml:"added,omitempty"`
+	Expires string `json:"expires,omitempty" yaml:"expires,omitempty"`
+	// MaxOccurrences and MaxPages (optional) limit how many links, and on how many pages, the rule ignores in a report.
+	// Above a threshold, the links are no longer ignored, and a finding is reported.
+	MaxOccurrences int `json:"maxOccurrences,omitempty" yaml:"maxOccurrences,omitempty"`
+	MaxPages       int `json:"maxPages,omitempty" yaml:"maxPages,omitempty"`
+	// source is the ignores file of the rule, or where an ad hoc rule was given, e.g. "--ignore", and index is the
+	// position of the rule in its source
+	source string
+	index  int
+	adHoc  bool
+}
+
+// origin describes where the rule comes from, e.g. "ignores.json rule 2", or "--ignore rule 0 (ad hoc)".
+func (rule *IgnoreRule) origin() string {
+	if rule.adHoc {
+		return fmt.Sprintf("%s rule %d (ad hoc)", rule.source, rule.index)
+	}
+	return fmt.Sprintf("%s rule %d", rule.source, rule.index)
+}
+
+const dateLayout = "2006-01-02"
+
+// now is a variable, so tests can use a fixed date for expiring rules.
+var now = time.Now
+
+func (rule *IgnoreRule) isMatch(page string, errorLink UrlErrorLink) bool {
+	if rule.isExpired() {
+		return false
+	}
+	if !errorLink.isMatch(UrlErrorLink{Url: rule.Url, Error: rule.Error}) {
+		return false
+	}
+	// the rule only applies on pages matching Page, and never on pages matching PageExclude
+	if rule.Page != "" && !isPatternMatch(rule.Page, page) {
+		return false
+	}
+	if rule.PageExclude != "" && isPatternMatch(rule.PageExclude, page) {
+		return false
+	}
+	if rule.Status != "" || rule.Category != "" {
+		category, status := classifyError(errorLink.Error)
+		if rule.Status != "" && !isStatusMatch(rule.Status, status) {
+			return false
+		}
+		if rule.Category != "" && errorCategory(rule.Category) != category {
+			return false
+		}
+	}
+	if rule.When != "" {
+		// invalid expressions are reported when the ignores file is loaded
+		expr, err := compileWhen(rule.When)
+		if err != nil || !expr.isMatch(page, errorLink) {
+			return false
+		}
+	}
+	return true
+}
+
+// isExpired reports whether the expiry date of the rule has passed. The rule still applies on the expiry date.
+func (rule *IgnoreRule) isExpired() bool {
+	if rule.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(dateLayout, rule.Expires, time.Local)
+	if err != nil {
+		return false
+	}
+	return !now().Before(expires.AddDate(0, 0, 1))
+}
+
+// literalPattern returns a regular expression matching exactly the given text.
+func literalPattern(text string) string {
+	return "^" + regexp.QuoteMeta(text) + "$"
+}
+
+// hostPattern returns a regular expression matching any url on the host of the given url.
+func hostPattern(linkUrl string) string {
+	u, err := url.Parse(linkUrl)
+	if err != nil || u.Host == "" {
+		return literalPattern(linkUrl)
+	}
+	return "^https?://" + regexp.QuoteMeta(u.Host) + "([/?#]|$)"
+}
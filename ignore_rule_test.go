@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreRuleIsMatchNoPage(t *testing.T) {
+	errLink := UrlErrorLink{"a", "b"}
+	assert.Equal(t, true, (&IgnoreRule{Url: "a", Error: "b"}).isMatch("anyPage", errLink))
+	assert.Equal(t, false, (&IgnoreRule{Url: "x", Error: "b"}).isMatch("anyPage", errLink))
+	assert.Equal(t, false, (&IgnoreRule{Url: "a", Error: "y"}).isMatch("anyPage", errLink))
+}
+
+func TestIgnoreRuleIsMatchPage(t *testing.T) {
+	errLink := UrlErrorLink{"https://foo.com/#content-wrapper", "id #content-wrapper not found"}
+	rule := IgnoreRule{Url: ".*#content-wrapper", Error: ".*", Page: "https://foo.com/search.*"}
+	assert.Equal(t, true, rule.isMatch("https://foo.com/search.html", errLink))
+	assert.Equal(t, true, rule.isMatch("https://foo.com/search", errLink))
+	assert.Equal(t, false, rule.isMatch("https://foo.com/index.html", errLink))
+}
+
+func TestIgnoreRuleIsMatchPageExclude(t *testing.T) {
+	errLink := UrlErrorLink{"https://foo.com/downloads", "404"}
+	rule := IgnoreRule{Url: "https://foo.com/downloads", Error: "404", PageExclude: "^https://foo.com/(index.html)?$"}
+	assert.Equal(t, true, rule.isMatch("https://foo.com/blog/archive/2019.html", errLink))
+	assert.Equal(t, false, rule.isMatch("https://foo.com/", errLink))
+	assert.Equal(t, false, rule.isMatch("https://foo.com/index.html", errLink))
+}
+
+func TestIgnoreRuleIsMatchPageAndPageExclude(t *testing.T) {
+	errLink := UrlErrorLink{"https://foo.com/downloads", "404"}
+	rule := IgnoreRule{Url: "https://foo.com/downloads", Error: "404", Page: "https://foo.com/blog/", PageExclude: "/blog/latest"}
+	assert.Equal(t, true, rule.isMatch("https://foo.com/blog/2019.html", errLink))
+	assert.Equal(t, false, rule.isMatch("https://foo.com/blog/latest.html", errLink))
+	assert.Equal(t, false, rule.isMatch("https://foo.com/index.html", errLink))
+}
+
+func TestIgnoreRuleIsMatchStatus(t *testing.T) {
+	rule := IgnoreRule{Url: "https://foo.com/", Status: "5xx"}
+	assert.Equal(t, true, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "503"}))
+	assert.Equal(t, true, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "500 (following redirect https://foo.com/b)"}))
+	assert.Equal(t, false, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "404"}))
+	// a timeout mentioning port 5003 is not a 5xx status
+	assert.Equal(t, false, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "dial tcp4 127.0.0.1:5003: i/o timeout"}))
+}
+
+func TestIgnoreRuleIsMatchCategory(t *testing.T) {
+	rule := IgnoreRule{Url: "https://foo.com/", Category: "timeout"}
+	assert.Equal(t, true, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "timeout"}))
+	assert.Equal(t, true, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "dialing to the given TCP address timed out"}))
+	assert.Equal(t, false, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "404"}))
+}
+
+func TestIgnoreRuleIsMatchStatusAndError(t *testing.T) {
+	rule := IgnoreRule{Url: "https://foo.com/", Error: "following redirect", Status: "403"}
+	assert.Equal(t, true, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "403 (following redirect https://foo.com/b)"}))
+	assert.Equal(t, false, rule.isMatch("page", UrlErrorLink{"https://foo.com/a", "403"}))
+}
+
+func TestIgnoreRuleIsExpired(t *testing.T) {
+	origNow := now
+	defer func() {
+		now = origNow
+	}()
+	now = func() time.Time { return time.Date(2026, 3, 15, 23, 59, 0, 0, time.Local) }
+
+	errLink := UrlErrorLink{"a", "b"}
+	assert.Equal(t, false, (&IgnoreRule{Url: "a", Error: "b"}).isExpired())
+	assert.Equal(t, false, (&IgnoreRule{Url: "a", Error: "b", Expires: "2026-03-15"}).isExpired())
+	assert.Equal(t, true, (&IgnoreRule{Url: "a", Error: "b", Expires: "2026-03-14"}).isExpired())
+	assert.Equal(t, false, (&IgnoreRule{Url: "a", Error: "b", Expires: "not a date"}).isExpired())
+	assert.Equal(t, true, (&IgnoreRule{Url: "a", Error: "b", Expires: "2026-03-15"}).isMatch("page", errLink))
+	assert.Equal(t, false, (&IgnoreRule{Url: "a", Error: "b", Expires: "2026-03-14"}).isMatch("page", errLink))
+}
+
+func TestLiteralPattern(t *testing.T) {
+	pattern := literalPattern("https://foo.com/a.html?b=1")
+	assert.Equal(t, `^https://foo\.com/a\.html\?b=1$`, pattern)
+	assert.True(t, isPatternMatch(pattern, "https://foo.com/a.html?b=1"))
+	assert.False(t, isPatternMatch(pattern, "https://foo.com/a.html?b=12"))
+	assert.False(t, isPatternMatch(pattern, "https://foo-com/a.html?b=1"))
+}
+
+func TestHostPattern(t *testing.T) {
+	pattern := hostPattern("https://cdn.example.com/a.js")
+	assert.True(t, isPatternMatch(pattern, "https://cdn.example.com/b.js"))
+	assert.True(t, isPatternMatch(pattern, "http://cdn.example.com"))
+	assert.True(t, isPatternMatch(pattern, "https://cdn.example.com?x=1"))
+	assert.False(t, isPatternMatch(pattern, "https://cdn.example.com.evil.com/"))
+	assert.Equal(t, literalPattern("not a url"), hostPattern("not a url"))
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type ignoresAddArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"Ignores file to change. Defaults to the nearest ignores file of the project"`
+	Url         string   `long:"url" description:"Url pattern of the rule" required:"true"`
+	Error       string   `long:"error" description:"Error pattern of the rule"`
+	Page        string   `long:"page" description:"Only ignore the link on pages matching this pattern"`
+	Status      string   `long:"status" description:"Status codes or classes, e.g. 404 or 5xx"`
+	Category    string   `long:"category" description:"Error category, e.g. timeout"`
+	Reason      string   `long:"reason" description:"Why the link is ignored"`
+	Expires     string   `long:"expires" description:"Expiry date (YYYY-MM-DD), or number of days (e.g. 90d)"`
+	Id          string   `long:"id" description:"Id of the rule, used to remove it. Defaults to a hash of the patterns"`
+	Literal     bool     `long:"literal" description:"Match the url, error and page exactly, instead of as regular expressions"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+type ignoresRemoveArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"Ignores file to change. Defaults to the nearest ignores file of the project"`
+	Id          string   `long:"id" description:"Id of the rule to remove"`
+	Index       *int     `long:"index" description:"Index of the rule to remove, counting from 0"`
+	Url         string   `long:"url" description:"Exact url pattern of the rule to remove"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+type ignoresListArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"File or http(s) url containing url errors to ignore. Can be repeated. Defaults to the same ignores files as the check"`
+	Link        string   `long:"link" description:"Only list the rules whose url pattern matches this link url"`
+	Reason      string   `long:"reason" description:"Only list the rules whose reason matches this regular expression"`
+	Expired     bool     `long:"expired" description:"Only list expired rules"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+const (
+	ignoresUsage       = "ignores add|remove|list [options]"
+	ignoresAddUsage    = "ignores add [options] --url <pattern> [--error <pattern>]"
+	ignoresRemoveUsage = "ignores remove [options] --id <id> | --index <index> | --url <pattern>"
+	ignoresListUsage   = "ignores list [options]"
+)
+
+// runIgnores manages the rules of an ignores file, without editing it by hand.
+func (c *commandFilter) runIgnores(ss []string) (bool, error) {
+	commands := map[string]func([]string) (bool, error){
+		"add":    c.runIgnoresAdd,
+		"remove": c.runIgnoresRemove,
+		"list":   c.runIgnoresList,
+	}
+	if len(ss) > 0 {
+		if run, ok := commands[ss[0]]; ok {
+			return run(ss[1:])
+		}
+	}
+	if len(ss) > 0 && (ss[0] == "-h" || ss[0] == "--help") {
+		c.print("Usage:\n  muffet-filter ", ignoresUsage)
+		return true, nil
+	}
+	return false, fmt.Errorf("expected a command: add, remove or list\n\nUsage:\n  muffet-filter %s", ignoresUsage)
+}
+
+// getRuleId returns a short id, derived from the patterns of the rule, so the same rule gets the same id.
+func getRuleId(rule IgnoreRule) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{rule.Url, rule.Error, rule.Page, rule.Status, rule.Category}, "\x00")))
+	return hex.EncodeToString(hash[:])[:8]
+}
+
+// newRule returns the rule given by the add arguments. Literal values are escaped into anchored patterns.
+func (args *ignoresAddArguments) newRule(today string) (rule IgnoreRule, err error) {
+	rule = IgnoreRule{Url: args.Url, Error: args.Error, Page: args.Page, Status: args.Status, Category: args.Category,
+		Reason: args.Reason, Added: today}
+	if args.Literal {
+		rule.Url = literalPattern(args.Url)
+		if args.Error != "" {
+			rule.Error = literalPattern(args.Error)
+		}
+		if args.Page != "" {
+			rule.Page = literalPattern(args.Page)
+		}
+	}
+	for _, pattern := range []string{rule.Url, rule.Error, rule.Page} {
+		if _, err = regexp.Compile(pattern); err != nil {
+			return rule, fmt.Errorf("invalid pattern: %s, use --literal to match it exactly: %w", pattern, err)
+		}
+	}
+	if rule.Status != "" && !isValidStatusPattern(rule.Status) {
+		return rule, fmt.Errorf("invalid status: %s, expected e.g. 404, 5xx or 401,403", rule.Status)
+	}
+	if rule.Category != "" && !slices.Contains(errorCategories, errorCategory(rule.Category)) {
+		return rule, fmt.Errorf("invalid category: %s, expected one of: %v", rule.Category, errorCategories)
+	}
+	rule.Id = args.Id
+	if rule.Id == "" {
+		rule.Id = getRuleId(rule)
+	}
+	return
+}
+
+// readIgnoreRules returns the rules of a single ignores file, without its includes. A missing file has no rules.
+func readIgnoreRules(ignoreListFile string) ([]IgnoreRule, error) {
+	content, err := os.ReadFile(ignoreListFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	doc, err := parseIgnoreDocument(ignoreListFile, content)
+	if err != nil {
+		return nil, err
+	}
+	file, err := decodeIgnoreFile(ignoreListFile, doc)
+	return file.Rules, err
+}
+
+func (c *commandFilter) runIgnoresAdd(ss []string) (bool, error) {
+	args := ignoresAddArguments{}
+	_, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&ignoresAddArguments{}, ignoresAddUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	today := now()
+	rule, err := args.newRule(today.Format(dateLayout))
+	if err != nil {
+		return false, err
+	}
+	if rule.Expires, err = getExpiryDate(args.Expires, today); err != nil {
+		return false, err
+	}
+	ignoreListFile, err := getIgnoresFileToEdit(&arguments{IgnoresJson: args.IgnoresJson})
+	if err != nil {
+		return false, err
+	}
+	rules, err := readIgnoreRules(ignoreListFile)
+	if err != nil {
+		return false, err
+	}
+	for i, existing := range rules {
+		if existing.Id == rule.Id {
+			return false, fmt.Errorf("%s rule %d already has the id %s", ignoreListFile, i, rule.Id)
+		} else if existing.isDuplicate(&rule) {
+			return false, fmt.Errorf("%s rule %d already ignores the same links", ignoreListFile, i)
+		}
+	}
+
+	if err = addIgnoreRules(ignoreListFile, []IgnoreRule{rule}); err != nil {
+		return false, err
+	}
+	c.print(fmt.Sprintf("added rule %s to %s: url: %s, error: %s", rule.Id, ignoreListFile, rule.Url, rule.Error))
+	return true, nil
+}
+
+// selectRule returns the index of the rule selected by the remove arguments: by its id, its index, or its exact url
+// pattern. Exactly one of them must be given, and the url pattern must select a single rule.
+func (args *ignoresRemoveArguments) selectRule(ignoreListFile string, rules []IgnoreRule) (int, error) {
+	selectors := 0
+	for _, isGiven := range []bool{args.Id != "", args.Index != nil, args.Url != ""} {
+		if isGiven {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return 0, fmt.Errorf("expected one of --id, --index or --url\n\n%s", commandHelp(&ignoresRemoveArguments{}, ignoresRemoveUsage))
+	}
+
+	switch {
+	case args.Index != nil:
+		if *args.Index < 0 || *args.Index >= len(rules) {
+			return 0, fmt.Errorf("no rule %d in %s, which has %d rules", *args.Index, ignoreListFile, len(rules))
+		}
+		return *args.Index, nil
+	case args.Id != "":
+		if i := slices.IndexFunc(rules, func(rule IgnoreRule) bool { return rule.Id == args.Id }); i >= 0 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("no rule of %s has the id: %s", ignoreListFile, args.Id)
+	}
+	var selected []int
+	for i, rule := range rules {
+		if rule.Url == args.Url {
+			selected = append(selected, i)
+		}
+	}
+	if len(selected) == 0 {
+		return 0, fmt.Errorf("no rule of %s has the url pattern: %s", ignoreListFile, args.Url)
+	} else if len(selected) > 1 {
+		return 0, fmt.Errorf("rules %v of %s have the url pattern: %s, use --id or --index", selected, ignoreListFile, args.Url)
+	}
+	return selected[0], nil
+}
+
+func (c *commandFilter) runIgnoresRemove(ss []string) (bool, error) {
+	args := ignoresRemoveArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&ignoresRemoveArguments{}, ignoresRemoveUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if len(remaining) != 0 {
+		return false, fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(&ignoresRemoveArguments{}, ignoresRemoveUsage))
+	}
+
+	ignoreListFile, err := getIgnoresFileToEdit(&arguments{IgnoresJson: args.IgnoresJson})
+	if err != nil {
+		return false, err
+	}
+	if itExists, _ := doesFileExist(ignoreListFile); !itExists {
+		return false, fmt.Errorf("ignores file not found: %s", ignoreListFile)
+	}
+	rules, err := readIgnoreRules(ignoreListFile)
+	if err != nil {
+		return false, err
+	}
+	index, err := args.selectRule(ignoreListFile, rules)
+	if err != nil {
+		return false, err
+	}
+	if err = removeIgnoreRule(ignoreListFile, index); err != nil {
+		return false, err
+	}
+	c.print(fmt.Sprintf("removed %s rule %d: url: %s, error: %s", ignoreListFile, index, rules[index].Url, rules[index].Error))
+	return true, nil
+}
+
+// String describes the rule on one line, for the list of rules.
+func (rule *IgnoreRule) String() string {
+	s := fmt.Sprintf("url: %s, error: %s", rule.Url, rule.Error)
+	for _, field := range []struct{ name, value string }{
+		{"page", rule.Page}, {"pageExclude", rule.PageExclude}, {"status", rule.Status}, {"category", rule.Category},
+		{"when", rule.When}, {"reason", rule.Reason}, {"expires", rule.Expires},
+	} {
+		if field.value != "" {
+			s += fmt.Sprintf(", %s: %s", field.name, field.value)
+		}
+	}
+	if rule.Id != "" {
+		s = "id: " + rule.Id + ", " + s
+	}
+	return s
+}
+
+func (c *commandFilter) runIgnoresList(ss []string) (bool, error) {
+	args := ignoresListArguments{}
+	_, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&ignoresListArguments{}, ignoresListUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	var reason *regexp.Regexp
+	if args.Reason != "" {
+		if reason, err = regexp.Compile(args.Reason); err != nil {
+			return false, fmt.Errorf("invalid reason pattern: %w", err)
+		}
+	}
+
+	ignores, err := loadIgnoresIfSiteKnown(&arguments{IgnoresJson: args.IgnoresJson})
+	if err != nil {
+		return false, err
+	}
+	rules := ignores.Rules
+	for _, rule := range rules {
+		if args.Link != "" && !isPatternMatch(rule.Url, args.Link) ||
+			reason != nil && !reason.MatchString(rule.Reason) ||
+			args.Expired && !rule.isExpired() {
+			continue
+		}
+		c.print(rule.origin(), ": ", rule.String())
+	}
+	return true, nil
+}
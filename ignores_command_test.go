@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ignoresToManage = `[
+  // partner site is down
+  {"url": "https://partner.com/", "error": "503", "reason": "partner outage", "expires": "2026-01-31"},
+  {"id": "blog", "url": "^https://blog\\.my-site\\.com/", "error": "404"}
+]
+`
+
+func setupIgnoresCommand(t *testing.T) (cf *commandFilter, stdout *bytes.Buffer, ignoreListFile string) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	origNow := now
+	t.Cleanup(func() {
+		now = origNow
+	})
+	now = func() time.Time { return time.Date(2026, 3, 15, 10, 0, 0, 0, time.Local) }
+
+	ignoreListFile = filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(ignoresToManage), 0644))
+	stdout = &bytes.Buffer{}
+	cf = newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	return
+}
+
+func TestIgnoresAdd(t *testing.T) {
+	cf, stdout, ignoreListFile := setupIgnoresCommand(t)
+
+	ok, err := cf.runWithError([]string{"ignores", "add", "-i", ignoreListFile, "--url", "https://my-site.com/a?b=1",
+		"--error", "404", "--reason", "moved", "--expires", "30d", "--literal"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "added rule ")
+
+	content, _ := os.ReadFile(ignoreListFile)
+	assert.Contains(t, string(content), "// partner site is down")
+	rules, err := readIgnoreRules(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(rules))
+	added := rules[2]
+	assert.Equal(t, `^https://my-site\.com/a\?b=1$`, added.Url)
+	assert.Equal(t, "^404$", added.Error)
+	assert.Equal(t, "2026-03-15", added.Added)
+	assert.Equal(t, "2026-04-14", added.Expires)
+	assert.Equal(t, getRuleId(added), added.Id)
+	assert.True(t, added.isMatch("https://my-site.com/", UrlErrorLink{Url: "https://my-site.com/a?b=1", Error: "404"}))
+
+	// the same rule again
+	_, err = cf.runWithError([]string{"ignores", "add", "-i", ignoreListFile, "--url", "https://my-site.com/a?b=1",
+		"--error", "404", "--literal"})
+	assert.EqualError(t, err, ignoreListFile+" rule 2 already has the id "+added.Id)
+	_, err = cf.runWithError([]string{"ignores", "add", "-i", ignoreListFile, "--url", "https://partner.com/", "--error", "503", "--id", "other"})
+	assert.EqualError(t, err, ignoreListFile+" rule 0 already ignores the same links")
+}
+
+func TestIgnoresAddInvalid(t *testing.T) {
+	cf, _, ignoreListFile := setupIgnoresCommand(t)
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--url", "https://my-site.com/a(b"}, "invalid pattern: https://my-site.com/a(b, use --literal to match it exactly: error parsing regexp: missing closing ): `https://my-site.com/a(b`"},
+		{[]string{"--url", "a", "--status", "4"}, "invalid status: 4, expected e.g. 404, 5xx or 401,403"},
+		{[]string{"--url", "a", "--category", "slow"}, "invalid category: slow, expected one of: [http-status timeout dns tls connection-refused fragment-not-found redirect-loop other]"},
+		{[]string{"--url", "a", "--expires", "soon"}, "invalid expiry: soon, expected a date (YYYY-MM-DD) or a number of days (e.g. 90d)"},
+		{[]string{"--error", "404"}, "the required flag `--url' was not specified"},
+	} {
+		_, err := cf.runWithError(append([]string{"ignores", "add", "-i", ignoreListFile}, tc.args...))
+		assert.EqualError(t, err, tc.expected)
+	}
+	content, _ := os.ReadFile(ignoreListFile)
+	assert.Equal(t, ignoresToManage, string(content))
+}
+
+func TestIgnoresRemove(t *testing.T) {
+	cf, stdout, ignoreListFile := setupIgnoresCommand(t)
+
+	ok, err := cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--id", "blog"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "removed "+ignoreListFile+" rule 1: url: ^https://blog\\.my-site\\.com/, error: 404\n", stdout.String())
+	content, _ := os.ReadFile(ignoreListFile)
+	assert.Equal(t, `[
+  // partner site is down
+  {"url": "https://partner.com/", "error": "503", "reason": "partner outage", "expires": "2026-01-31"}
+]
+`, string(content))
+
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--url", "https://other.com/"})
+	assert.EqualError(t, err, "no rule of "+ignoreListFile+" has the url pattern: https://other.com/")
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--id", "blog"})
+	assert.EqualError(t, err, "no rule of "+ignoreListFile+" has the id: blog")
+
+	ok, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--url", "https://partner.com/"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	content, _ = os.ReadFile(ignoreListFile)
+	assert.Equal(t, "[\n]\n", string(content))
+}
+
+func TestIgnoresRemoveSelectors(t *testing.T) {
+	cf, _, ignoreListFile := setupIgnoresCommand(t)
+	// the url pattern of the first rule is the index of the second one
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[
+  {"url": "1", "error": "404"},
+  {"url": "https://a.com/", "error": "503"},
+  {"url": "https://a.com/", "error": "404"}
+]`), 0644))
+
+	_, err := cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--url", "1"})
+	assert.Nil(t, err)
+	rules, _ := readIgnoreRules(ignoreListFile)
+	assert.Equal(t, []IgnoreRule{{Url: "https://a.com/", Error: "503"}, {Url: "https://a.com/", Error: "404"}}, rules)
+
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--url", "https://a.com/"})
+	assert.EqualError(t, err, "rules [0 1] of "+ignoreListFile+" have the url pattern: https://a.com/, use --id or --index")
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--index", "2"})
+	assert.EqualError(t, err, "no rule 2 in "+ignoreListFile+", which has 2 rules")
+	for _, args := range [][]string{{}, {"--index", "0", "--url", "https://a.com/"}} {
+		_, err = cf.runWithError(append([]string{"ignores", "remove", "-i", ignoreListFile}, args...))
+		assert.ErrorContains(t, err, "expected one of --id, --index or --url")
+	}
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "0"})
+	assert.ErrorContains(t, err, "invalid number of arguments")
+
+	_, err = cf.runWithError([]string{"ignores", "remove", "-i", ignoreListFile, "--index", "1"})
+	assert.Nil(t, err)
+	content, _ := os.ReadFile(ignoreListFile)
+	assert.Equal(t, `[
+  {"url": "https://a.com/", "error": "503"}
+]`, string(content))
+}
+
+func TestIgnoresList(t *testing.T) {
+	cf, stdout, ignoreListFile := setupIgnoresCommand(t)
+
+	ok, err := cf.runWithError([]string{"ignores", "list", "-i", ignoreListFile})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ignoreListFile+" rule 0: url: https://partner.com/, error: 503, reason: partner outage, expires: 2026-01-31\n"+
+		ignoreListFile+" rule 1: id: blog, url: ^https://blog\\.my-site\\.com/, error: 404\n", stdout.String())
+
+	for _, filter := range [][]string{{"--expired"}, {"--reason", "outage"}, {"--link", "https://partner.com/"}} {
+		stdout.Reset()
+		_, err = cf.runWithError(append([]string{"ignores", "list", "-i", ignoreListFile}, filter...))
+		assert.Nil(t, err)
+		assert.Equal(t, ignoreListFile+" rule 0: url: https://partner.com/, error: 503, reason: partner outage, expires: 2026-01-31\n", stdout.String(), filter)
+	}
+
+	_, err = cf.runWithError([]string{"ignores"})
+	assert.EqualError(t, err, "expected a command: add, remove or list\n\nUsage:\n  muffet-filter ignores add|remove|list [options]")
+}
+
+func TestIgnoresListSitePatterns(t *testing.T) {
+	cf, stdout, ignoreListFile := setupIgnoresCommand(t)
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[
+  {"url": "site:/downloads/", "error": "404"},
+  {"url": "^${SITE_URL}/blog/", "error": "503"}
+]`), 0644))
+
+	// without the url of the website, the rules are listed as written
+	t.Setenv(siteUrlVariable, "")
+	ok, err := cf.runWithError([]string{"ignores", "list", "-i", ignoreListFile})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ignoreListFile+" rule 0: url: site:/downloads/, error: 404\n"+
+		ignoreListFile+" rule 1: url: ^${SITE_URL}/blog/, error: 503\n", stdout.String())
+
+	t.Setenv(siteUrlVariable, "https://my-site.com/")
+	stdout.Reset()
+	_, err = cf.runWithError([]string{"ignores", "list", "-i", ignoreListFile, "--link", "https://my-site.com/blog/post"})
+	assert.Nil(t, err)
+	assert.Equal(t, ignoreListFile+" rule 1: url: ^https://my-site\\.com/blog/, error: 503\n", stdout.String())
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v3"
+)
+
+type importArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"Ignores file to add the imported rules to. Defaults to the nearest ignores file of the project"`
+	From        string   `long:"from" description:"Format of the files to import, detected by the file name by default" choice:"lycheeignore" choice:"lychee" choice:"markdown-link-check" choice:"htmltest"`
+	DryRun      bool     `long:"dry-run" description:"Only print the imported rules, without changing the ignores file"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+const importUsage = "import [options] <.lycheeignore|lychee.toml|markdown-link-check json|.htmltest.yml>..."
+
+const (
+	importLycheeIgnore      = "lycheeignore"
+	importLychee            = "lychee"
+	importMarkdownLinkCheck = "markdown-link-check"
+	importHtmltest          = "htmltest"
+)
+
+// getImportFormat detects the link checker of a configuration file by its name.
+func getImportFormat(file string) (string, error) {
+	name := strings.ToLower(filepath.Base(file))
+	switch {
+	case name == ".lycheeignore":
+		return importLycheeIgnore, nil
+	case strings.HasSuffix(name, ".toml"):
+		return importLychee, nil
+	case strings.HasSuffix(name, ".json"):
+		return importMarkdownLinkCheck, nil
+	case strings.HasSuffix(name, ".yml"), strings.HasSuffix(name, ".yaml"):
+		return importHtmltest, nil
+	}
+	return "", fmt.Errorf("cannot detect the format of %s, use --from", file)
+}
+
+// importer collects the rules converted from the configuration of another link checker, and warnings about the
+// settings which do not translate exactly.
+type importer struct {
+	file     string
+	rules    []IgnoreRule
+	warnings []string
+}
+
+func (imp *importer) warn(format string, a ...any) {
+	imp.warnings = append(imp.warnings, fmt.Sprintf("%s: %s", imp.file, fmt.Sprintf(format, a...)))
+}
+
+// addPattern adds a rule ignoring any error of the links matching the url pattern. Other link checkers exclude the
+// links from checking instead, which gives the same result, except that the links are still requested.
+func (imp *importer) addPattern(pattern, setting string) {
+	if pattern == "" {
+		return
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		imp.warn("%s %s skipped, not a valid go regular expression: %v", setting, pattern, err)
+		return
+	}
+	imp.rules = append(imp.rules, IgnoreRule{Url: pattern, Reason: "imported from " + filepath.Base(imp.file)})
+}
+
+// importLycheeIgnoreFile converts a .lycheeignore file: one regular expression per line, and # comments.
+func (imp *importer) importLycheeIgnoreFile(content []byte) {
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			imp.addPattern(line, "pattern")
+		}
+	}
+}
+
+// lycheeUntranslatable are the exclusion settings of lychee.toml, which have no equivalent ignore rule.
+var lycheeUntranslatable = map[string]string{
+	"exclude_path":        "excludes input files, check fewer pages with muffet options instead",
+	"include":             "include patterns override excludes, which ignore rules cannot express",
+	"exclude_all_private": "excludes private ip addresses, add rules for their urls instead",
+	"exclude_private":     "excludes private ip addresses, add rules for their urls instead",
+	"exclude_link_local":  "excludes link-local ip addresses, add rules for their urls instead",
+	"exclude_loopback":    "excludes loopback addresses, add rules for their urls instead",
+	"exclude_mail":        "excludes email addresses, which muffet does not check",
+}
+
+func (imp *importer) importLycheeToml(content []byte) error {
+	var values map[string]any
+	if err := toml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("%s: %w", imp.file, err)
+	}
+	if patterns, ok := values["exclude"].([]any); ok {
+		for _, pattern := range patterns {
+			if s, ok := pattern.(string); ok {
+				imp.addPattern(s, "exclude")
+			}
+		}
+	}
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		reason, ok := lycheeUntranslatable[key]
+		if value := values[key]; ok && value != false && !isEmptyTomlArray(value) {
+			imp.warn("%s not translated, it %s", key, reason)
+		}
+	}
+	return nil
+}
+
+func isEmptyTomlArray(value any) bool {
+	array, ok := value.([]any)
+	return ok && len(array) == 0
+}
+
+// markdownLinkCheckConfig is the configuration of markdown-link-check. Patterns are javascript regular expressions.
+type markdownLinkCheckConfig struct {
+	IgnorePatterns []struct {
+		Pattern string `json:"pattern"`
+	} `json:"ignorePatterns"`
+	ReplacementPatterns []struct {
+		Pattern     string `json:"pattern"`
+		Replacement string `json:"replacement"`
+	} `json:"replacementPatterns"`
+	AliveStatusCodes []int `json:"aliveStatusCodes"`
+}
+
+func (imp *importer) importMarkdownLinkCheck(content []byte) error {
+	var config markdownLinkCheckConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("%s: %w", imp.file, err)
+	}
+	for _, ignore := range config.IgnorePatterns {
+		imp.addPattern(ignore.Pattern, "ignorePattern")
+	}
+	for _, replacement := range config.ReplacementPatterns {
+		imp.warn("replacementPattern %s not translated, links are checked as they appear on the page", replacement.Pattern)
+	}
+	if len(config.AliveStatusCodes) > 0 {
+		imp.warn("aliveStatusCodes not translated, add rules with a status, e.g. {\"url\": \".*\", \"status\": \"403\"}")
+	}
+	return nil
+}
+
+func (imp *importer) importHtmltest(content []byte) error {
+	var config map[string]any
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("%s: %w", imp.file, err)
+	}
+	var keys []string
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch values, _ := config[key].([]any); key {
+		case "IgnoreURLs":
+			for _, value := range values {
+				imp.addPattern(fmt.Sprint(value), "IgnoreURLs")
+			}
+		case "IgnoreInternalURLs":
+			// internal urls are paths of the website, which are matched exactly
+			for _, value := range values {
+				if path := fmt.Sprint(value); strings.HasPrefix(path, "/") {
+					imp.addPattern(regexp.QuoteMeta(path)+"$", "IgnoreInternalURLs")
+				} else {
+					imp.warn("IgnoreInternalURLs %s skipped, expected a path starting with /", path)
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "Ignore") && config[key] != false {
+				imp.warn("%s not translated", key)
+			}
+		}
+	}
+	return nil
+}
+
+func (imp *importer) importFile(format string, content []byte) error {
+	switch format {
+	case importLycheeIgnore:
+		imp.importLycheeIgnoreFile(content)
+		return nil
+	case importLychee:
+		return imp.importLycheeToml(content)
+	case importMarkdownLinkCheck:
+		return imp.importMarkdownLinkCheck(content)
+	default:
+		return imp.importHtmltest(content)
+	}
+}
+
+// runImport converts the exclusions of other link checkers into ignore rules, and adds them to the ignores file.
+func (c *commandFilter) runImport(ss []string) (bool, error) {
+	args := importArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&importArguments{}, importUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if len(remaining) == 0 {
+		return false, fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(&importArguments{}, importUsage))
+	}
+
+	var rules []IgnoreRule
+	for _, file := range remaining {
+		format := args.From
+		if format == "" {
+			if format, err = getImportFormat(file); err != nil {
+				return false, err
+			}
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return false, err
+		}
+		imp := importer{file: file}
+		if err = imp.importFile(format, content); err != nil {
+			return false, err
+		}
+		for _, warning := range imp.warnings {
+			c.print("warning: ", warning)
+		}
+		rules = append(rules, imp.rules...)
+	}
+
+	if args.DryRun {
+		prettyJson, err := json.MarshalIndent(rules, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		c.print(string(prettyJson))
+		return true, nil
+	}
+
+	ignoreListFile, err := getIgnoresFileToEdit(&arguments{IgnoresJson: args.IgnoresJson})
+	if err != nil {
+		return false, err
+	}
+	existing, err := readIgnoreRules(ignoreListFile)
+	if err != nil {
+		return false, err
+	}
+	var added []IgnoreRule
+	for _, rule := range rules {
+		isDuplicate := func(other IgnoreRule) bool { return other.isDuplicate(&rule) }
+		if !slices.ContainsFunc(existing, isDuplicate) && !slices.ContainsFunc(added, isDuplicate) {
+			added = append(added, rule)
+		}
+	}
+	if len(added) > 0 {
+		if err = addIgnoreRules(ignoreListFile, added); err != nil {
+			return false, err
+		}
+	}
+	c.print(fmt.Sprintf("imported %d rules into %s, skipped %d duplicates", len(added), ignoreListFile, len(rules)-len(added)))
+	return true, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func importTestFile(t *testing.T, file string) importer {
+	format, err := getImportFormat(file)
+	assert.Nil(t, err)
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	imp := importer{file: file}
+	assert.Nil(t, imp.importFile(format, content))
+	return imp
+}
+
+func getImportedUrls(imp importer) (urls []string) {
+	for _, rule := range imp.rules {
+		urls = append(urls, rule.Url)
+	}
+	return
+}
+
+func TestImportLycheeIgnore(t *testing.T) {
+	imp := importTestFile(t, "testdata/import/.lycheeignore")
+	assert.Equal(t, []string{`https://staging\.my-site\.com/`, `^https://www\.linkedin\.com/`}, getImportedUrls(imp))
+	assert.Equal(t, "imported from .lycheeignore", imp.rules[0].Reason)
+	assert.Equal(t, []string{"testdata/import/.lycheeignore: pattern (?<!foo)bar skipped, not a valid go regular expression: error parsing regexp: invalid named capture: `(?<!foo)bar`"},
+		imp.warnings)
+}
+
+func TestImportLycheeToml(t *testing.T) {
+	imp := importTestFile(t, "testdata/import/lychee.toml")
+	assert.Equal(t, []string{`^https://www\.linkedin\.com/`, `https://example\.com/private`, `^https://twitter\.com/[^/]+/status/`},
+		getImportedUrls(imp))
+	assert.Equal(t, []string{
+		"testdata/import/lychee.toml: exclude_loopback not translated, it excludes loopback addresses, add rules for their urls instead",
+		"testdata/import/lychee.toml: exclude_path not translated, it excludes input files, check fewer pages with muffet options instead",
+	}, imp.warnings)
+}
+
+func TestImportMarkdownLinkCheck(t *testing.T) {
+	imp := importTestFile(t, "testdata/import/markdown-link-check.json")
+	assert.Equal(t, []string{`^http://localhost`, `^https://twitter\.com/`}, getImportedUrls(imp))
+	assert.Equal(t, []string{
+		"testdata/import/markdown-link-check.json: replacementPattern ^/ not translated, links are checked as they appear on the page",
+		`testdata/import/markdown-link-check.json: aliveStatusCodes not translated, add rules with a status, e.g. {"url": ".*", "status": "403"}`,
+	}, imp.warnings)
+}
+
+func TestImportHtmltest(t *testing.T) {
+	imp := importTestFile(t, "testdata/import/.htmltest.yml")
+	assert.Equal(t, []string{`/misc/old\.html$`, `example.com`, `^https://fonts\.googleapis\.com/`}, getImportedUrls(imp))
+	assert.Equal(t, []string{
+		"testdata/import/.htmltest.yml: IgnoreAltMissing not translated",
+		"testdata/import/.htmltest.yml: IgnoreDirs not translated",
+		"testdata/import/.htmltest.yml: IgnoreInternalURLs misc.html skipped, expected a path starting with /",
+	}, imp.warnings)
+}
+
+func TestImportLycheeTomlInvalid(t *testing.T) {
+	imp := &importer{file: "lychee.toml"}
+	assert.EqualError(t, imp.importLycheeToml([]byte("exclude = ['x")), `lychee.toml: toml: line 1 (last key "exclude"): unexpected EOF; expected "'"`)
+}
+
+func TestRunImport(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[{"url": "^https://www\\.linkedin\\.com/"}]`), 0644))
+
+	ok, err := cf.runWithError([]string{"import", "-i", ignoreListFile, "testdata/import/.lycheeignore", "testdata/import/lychee.toml"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "warning: testdata/import/lychee.toml: exclude_path not translated")
+	assert.Contains(t, stdout.String(), "imported 3 rules into "+ignoreListFile+", skipped 2 duplicates")
+	rules, err := readIgnoreRules(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(rules))
+
+	stdout.Reset()
+	ok, err = cf.runWithError([]string{"import", "--dry-run", "--from", "lycheeignore", "testdata/import/.lycheeignore"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), `"reason": "imported from .lycheeignore"`)
+
+	_, err = cf.runWithError([]string{"import", "ignores.txt"})
+	assert.EqualError(t, err, "cannot detect the format of ignores.txt, use --from")
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v3"
+)
+
+// jsoncSyntaxError is a syntax error at a line and column of a JSON with comments document.
+type jsoncSyntaxError struct {
+	line, column int
+	msg          string
+}
+
+func (e *jsoncSyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.line, e.column, e.msg)
+}
+
+var hujsonSyntaxError = regexp.MustCompile(`^hujson: line (\d+), column (\d+): (.*)$`)
+
+// parseJsonc parses JSON with comments (// and /* */) and trailing commas into a yaml node tree, so json and yaml
+// ignores files are decoded alike. Comments are kept verbatim on the nodes, so the document can be written back
+// without losing them: the comments on the lines before a value are its head comment, the comments after it on the
+// same line its line comment, and the comments before a closing bracket the foot comment of the object or array.
+// Comments between a name and its colon are the line comment of the name, and comments after the colon the head
+// comment of the value.
+func parseJsonc(data []byte) (*yaml.Node, error) {
+	root, err := hujson.Parse(data)
+	if err != nil {
+		// hujson syntax errors look like "hujson: line 3, column 5: invalid character ..."
+		if match := hujsonSyntaxError.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			column, _ := strconv.Atoi(match[2])
+			return nil, &jsoncSyntaxError{line, column, match[3]}
+		}
+		return nil, err
+	}
+
+	node := newJsoncNode(data, &root)
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{node}}
+	doc.HeadComment = getJsoncComments(root.BeforeExtra)
+	node.LineComment, doc.FootComment = splitJsoncExtra(root.AfterExtra)
+	return doc, nil
+}
+
+// jsoncItem is an element of an array, or a member of an object, from its first to its last value.
+type jsoncItem struct {
+	first, last *hujson.Value
+}
+
+// newJsoncNode converts a hujson value into a yaml node, at the position of the value in the data.
+func newJsoncNode(data []byte, v *hujson.Value) *yaml.Node {
+	node := &yaml.Node{
+		Line:   1 + bytes.Count(data[:v.StartOffset], []byte("\n")),
+		Column: v.StartOffset - bytes.LastIndexByte(data[:v.StartOffset], '\n'),
+	}
+
+	var items []jsoncItem
+	var closingExtra hujson.Extra
+	switch value := v.Value.(type) {
+	case *hujson.Object:
+		node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		for i := range value.Members {
+			member := &value.Members[i]
+			key, item := newJsoncNode(data, &member.Name), newJsoncNode(data, &member.Value)
+			key.LineComment, item.HeadComment = getJsoncComments(member.Name.AfterExtra), getJsoncComments(member.Value.BeforeExtra)
+			node.Content = append(node.Content, key, item)
+			items = append(items, jsoncItem{&member.Name, &member.Value})
+		}
+		closingExtra = value.AfterExtra
+	case *hujson.Array:
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+		for i := range value.Elements {
+			node.Content = append(node.Content, newJsoncNode(data, &value.Elements[i]))
+			items = append(items, jsoncItem{&value.Elements[i], &value.Elements[i]})
+		}
+		closingExtra = value.AfterExtra
+	case hujson.Literal:
+		node.Kind, node.Value = yaml.ScalarNode, string(value)
+		switch value.Kind() {
+		case '"':
+			node.Tag, node.Style, node.Value = "!!str", yaml.DoubleQuotedStyle, value.String()
+		case '0':
+			node.Tag = "!!int"
+			if bytes.ContainsAny(value, ".eE") {
+				node.Tag = "!!float"
+			}
+		case 'n':
+			node.Tag = "!!null"
+		default:
+			node.Tag = "!!bool"
+		}
+		return node
+	}
+
+	// the comments after an item on the same line, also after its comma, belong to the item
+	step := len(node.Content) / max(len(items), 1)
+	var previous *yaml.Node
+	for i, item := range items {
+		first, last := node.Content[i*step], node.Content[i*step+step-1]
+		sameLine, ownLine := splitJsoncExtra(item.first.BeforeExtra)
+		if previous == nil {
+			// comments after the opening bracket go to the first item
+			ownLine = joinJsoncComments(sameLine, ownLine)
+		} else {
+			previous.LineComment = joinJsoncComments(previous.LineComment, sameLine)
+		}
+		first.HeadComment = joinJsoncComments(ownLine, first.HeadComment)
+		// comments before the comma
+		last.LineComment = getJsoncComments(item.last.AfterExtra)
+		previous = last
+	}
+	sameLine, ownLine := splitJsoncExtra(closingExtra)
+	if previous == nil {
+		ownLine = joinJsoncComments(sameLine, ownLine)
+	} else {
+		previous.LineComment = joinJsoncComments(previous.LineComment, sameLine)
+	}
+	node.FootComment = ownLine
+	return node
+}
+
+// splitJsoncComments returns the comments in whitespace and comments. Each line of text without comment syntax, e.g.
+// a yaml comment, is a // comment.
+func splitJsoncComments(text string) (comments []string) {
+	for {
+		text = strings.TrimLeft(text, " \t\r\n")
+		switch {
+		case text == "":
+			return
+		case strings.HasPrefix(text, "/*"):
+			end := strings.Index(text, "*/") + len("*/")
+			if end < len("*/") {
+				end = len(text)
+			}
+			comments = append(comments, text[:end])
+			text = text[end:]
+		default:
+			end := strings.IndexByte(text, '\n')
+			if end < 0 {
+				end = len(text)
+			}
+			comment := strings.TrimRight(text[:end], " \t\r")
+			if !strings.HasPrefix(comment, "//") {
+				comment = "//" + strings.TrimPrefix(comment, "#")
+			}
+			comments = append(comments, comment)
+			text = text[end:]
+		}
+	}
+}
+
+// splitJsoncExtra returns the comments of the whitespace and comments after a value: those on the line of the value,
+// and those on the following lines.
+func splitJsoncExtra(extra hujson.Extra) (sameLine, ownLine string) {
+	text := string(extra)
+	var same, own []string
+	for _, comment := range splitJsoncComments(text) {
+		offset := strings.Index(text, comment)
+		if len(own) == 0 && !strings.Contains(text[:offset], "\n") {
+			same = append(same, comment)
+		} else {
+			own = append(own, comment)
+		}
+		text = text[offset+len(comment):]
+	}
+	return strings.Join(same, "\n"), strings.Join(own, "\n")
+}
+
+func getJsoncComments(extra hujson.Extra) string {
+	return strings.Join(splitJsoncComments(string(extra)), "\n")
+}
+
+func joinJsoncComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n" + b
+}
+
+// marshalJsonc writes the yaml node tree as JSON, with the comments of the nodes.
+func marshalJsonc(doc *yaml.Node) []byte {
+	b := &bytes.Buffer{}
+	node := doc
+	if doc.Kind == yaml.DocumentNode {
+		writeJsoncComments(b, doc.HeadComment, 0)
+		node = doc.Content[0]
+	}
+	writeJsoncNode(b, node, 0)
+	writeJsoncInlineComments(b, splitJsoncComments(node.LineComment), 0, false)
+	b.WriteString("\n")
+	if doc.Kind == yaml.DocumentNode {
+		writeJsoncComments(b, doc.FootComment, 0)
+	}
+	return b.Bytes()
+}
+
+// writeJsoncComments writes the comments on lines of their own.
+func writeJsoncComments(b *bytes.Buffer, comments string, indent int) {
+	for _, comment := range splitJsoncComments(comments) {
+		b.WriteString(strings.Repeat("  ", indent) + comment + "\n")
+	}
+}
+
+// writeJsoncInlineComments writes the comments after a value on the same line. A // comment ends the line, so what
+// follows, e.g. a colon, continues on the next line. It reports whether the line was broken.
+func writeJsoncInlineComments(b *bytes.Buffer, comments []string, indent int, isContinued bool) (isBroken bool) {
+	for i, comment := range comments {
+		b.WriteString(" " + comment)
+		isBroken = strings.HasPrefix(comment, "//") && (isContinued || i+1 < len(comments))
+		if isBroken {
+			b.WriteString("\n" + strings.Repeat("  ", indent))
+		}
+	}
+	return
+}
+
+func writeJsoncNode(b *bytes.Buffer, node *yaml.Node, indent int) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		writeJsoncNode(b, node.Alias, indent)
+	case yaml.MappingNode, yaml.SequenceNode:
+		opening, closing, step := "[", "]", 1
+		if node.Kind == yaml.MappingNode {
+			opening, closing, step = "{", "}", 2
+		}
+		if len(node.Content) == 0 && node.FootComment == "" {
+			b.WriteString(opening + closing)
+			return
+		}
+		b.WriteString(opening + "\n")
+		prefix := strings.Repeat("  ", indent+1)
+		for i := 0; i < len(node.Content); i += step {
+			item := node.Content[i]
+			writeJsoncComments(b, item.HeadComment, indent+1)
+			b.WriteString(prefix)
+			if node.Kind == yaml.MappingNode {
+				writeJsoncNode(b, item, indent+1)
+				writeJsoncInlineComments(b, splitJsoncComments(item.LineComment), indent+1, true)
+				b.WriteString(":")
+				item = node.Content[i+1]
+				if !writeJsoncInlineComments(b, splitJsoncComments(item.HeadComment), indent+2, true) {
+					b.WriteString(" ")
+				}
+			}
+			writeJsoncNode(b, item, indent+1)
+			// block comments stay before the comma, and // comments after it
+			comments := splitJsoncComments(item.LineComment)
+			blockComments := 0
+			for blockComments < len(comments) && strings.HasPrefix(comments[blockComments], "/*") {
+				blockComments++
+			}
+			writeJsoncInlineComments(b, comments[:blockComments], indent+1, false)
+			if i+step < len(node.Content) {
+				b.WriteString(",")
+			}
+			writeJsoncInlineComments(b, comments[blockComments:], indent+1, false)
+			b.WriteString("\n")
+			if item.Kind == yaml.ScalarNode {
+				// foot comments of collections are written before their closing bracket
+				writeJsoncComments(b, item.FootComment, indent+1)
+			}
+		}
+		writeJsoncComments(b, node.FootComment, indent+1)
+		b.WriteString(strings.Repeat("  ", indent) + closing)
+	default:
+		switch node.ShortTag() {
+		case "!!int", "!!float", "!!bool", "!!null":
+			b.WriteString(node.Value)
+		default:
+			e := json.NewEncoder(b)
+			e.SetEscapeHTML(false)
+			_ = e.Encode(node.Value)
+			// Encode adds a newline
+			b.Truncate(b.Len() - 1)
+		}
+	}
+}
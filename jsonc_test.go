@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseJsonc(t *testing.T) {
+	doc, err := parseJsonc([]byte(`// head
+[
+  // first
+  {"url": "a&b", "error": 404, "ok": true, "n": null, "f": -1.5e3}, // trailing
+  /* block
+     comment */
+  "x",
+  // foot
+]`))
+	assert.Nil(t, err)
+	assert.Equal(t, "// head", doc.HeadComment)
+
+	root := doc.Content[0]
+	assert.Equal(t, yaml.SequenceNode, root.Kind)
+	assert.Equal(t, 2, root.Line)
+	assert.Equal(t, "// foot", root.FootComment)
+	assert.Equal(t, 2, len(root.Content))
+
+	first := root.Content[0]
+	assert.Equal(t, "// first", first.HeadComment)
+	assert.Equal(t, "// trailing", first.LineComment)
+	assert.Equal(t, 4, first.Line)
+	assert.Equal(t, 3, first.Column)
+	var value map[string]any
+	assert.Nil(t, first.Decode(&value))
+	assert.Equal(t, map[string]any{"url": "a&b", "error": 404, "ok": true, "n": nil, "f": -1500.0}, value)
+
+	second := root.Content[1]
+	assert.Equal(t, "/* block\n     comment */", second.HeadComment)
+	assert.Equal(t, "x", second.Value)
+}
+
+func TestParseJsoncErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{"", "line 1, column 1: parsing value: unexpected EOF"},
+		{"[\n  {\"url\": \"a\"\n  \"error\": \"b\"}]", "line 3, column 3: invalid character '\"' after object value (expecting ',' or '}')"},
+		{"[\n  {url: \"a\"}]", "line 2, column 4: invalid literal: url"},
+		{"{\"url\" \"a\"}", "line 1, column 8: invalid character '\"' after object name"},
+		{"[\"abc]", "line 1, column 7: parsing string: unexpected EOF"},
+		{"[1.2.3]", "line 1, column 2: invalid literal: 1.2.3"},
+		{"[] []", "line 1, column 4: invalid character '[' after top-level value"},
+		{"[/* open", "line 1, column 2: parsing comment: unexpected EOF"},
+	} {
+		_, err := parseJsonc([]byte(tc.input))
+		assert.EqualError(t, err, tc.err, tc.input)
+	}
+}
+
+func TestMarshalJsoncRoundTrip(t *testing.T) {
+	input := `// head
+{
+  "include": [], // none
+  /* the
+     rules */
+  "rules": [
+    // first
+    {
+      "url" /* before colon */: /* after colon */ "https://foo.com/?a=1&b=<2>",
+      "error": "403" /* before comma */, // forbidden
+      "status" // before colon
+      : "4xx"
+    },
+    {
+      "url": "b",
+      "error": "404",
+      "n": 1
+    }
+    // foot
+  ],
+  "expectations": [
+    /* none yet */
+  ]
+} // end
+// tail
+`
+	doc, err := parseJsonc([]byte(input))
+	assert.Nil(t, err)
+	assert.Equal(t, input, string(marshalJsonc(doc)))
+}
+
+func TestMarshalJsoncKeepsComments(t *testing.T) {
+	input := `/* head */ {"rules": [ // rules
+  /* a */ {"url" /* b */ : // c
+  "x" /* d */ , /* e */ "error": "404" /* f */} /* g */, // h
+  /* i */ ] /* j */ } /* k */`
+	doc, err := parseJsonc([]byte(input))
+	assert.Nil(t, err)
+	output := marshalJsonc(doc)
+	assert.Equal(t, `/* head */
+{
+  "rules": [
+    // rules
+    /* a */
+    {
+      "url" /* b */: // c
+        "x" /* d */ /* e */,
+      "error": "404" /* f */
+    } /* g */ // h
+    /* i */
+  ] /* j */
+} /* k */
+`, string(output))
+
+	// formatting is stable
+	doc, err = parseJsonc(output)
+	assert.Nil(t, err)
+	assert.Equal(t, string(output), string(marshalJsonc(doc)))
+}
+
+func TestMarshalJsoncTrailingCommas(t *testing.T) {
+	doc, err := parseJsonc([]byte(`[{"url": "a", "error": "b",},]`))
+	assert.Nil(t, err)
+	assert.Equal(t, `[
+  {
+    "url": "a",
+    "error": "b"
+  }
+]
+`, string(marshalJsonc(doc)))
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type lintArguments struct {
+	IgnoresJson     []string `short:"i" long:"ignores" description:"Ignores file or http(s) url to lint. Can be repeated. Defaults to the same ignores files as the check"`
+	RequireMetadata []string `long:"require" description:"Metadata field every rule must have: reason, added or expires. Can be repeated"`
+	Help            bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+const lintUsage = "lint [options] [ignores files]"
+
+const (
+	lintInvalidRegex    = "invalid-regex"
+	lintDuplicate       = "duplicate"
+	lintShadowed        = "shadowed"
+	lintBroad           = "broad"
+	lintUnescapedDot    = "unescaped-dot"
+	lintNeverMatches    = "never-matches"
+	lintMissingMetadata = "missing-metadata"
+)
+
+var metadataFields = []string{"reason", "added", "expires"}
+
+// lintFinding is a problem of a rule, found by the linter.
+type lintFinding struct {
+	Source  string `json:"source"`
+	Rule    int    `json:"rule"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// broadUrlProbe is a url no rule should match, unless it matches the urls of any host.
+const broadUrlProbe = "https://lint-probe.invalid/"
+
+var hostPatternPart = regexp.MustCompile(`://([^/?#()\[\]|]*)`)
+var unescapedDot = regexp.MustCompile(`(^|[^\\])\.`)
+var bareHostPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+(\\?\.[a-zA-Z0-9-]+)+$`)
+var statusWithText = regexp.MustCompile(`[1-5]\d\d [A-Za-z]`)
+
+// getPatternSample returns a text the pattern is meant to match: the text of a literal pattern like "^text$", or
+// else the pattern itself, as patterns are often urls, which also match by equality.
+func getPatternSample(pattern string) string {
+	if strings.HasPrefix(pattern, "^") && strings.HasSuffix(pattern, "$") {
+		inner := pattern[1 : len(pattern)-1]
+		text := regexp.MustCompile(`\\(.)`).ReplaceAllString(inner, "$1")
+		if regexp.QuoteMeta(text) == inner {
+			return text
+		}
+	}
+	return pattern
+}
+
+// isBroaderField reports whether the pattern of an earlier rule matches everything the later pattern is meant to match.
+func isBroaderField(earlier, later string) bool {
+	return earlier == later || isPatternMatch(earlier, getPatternSample(later))
+}
+
+// shadows reports whether the earlier rule ignores every link the later rule ignores, so the later rule is never used.
+// An earlier rule with a when expression or a threshold does not ignore all links matching its patterns.
+func (rule *IgnoreRule) shadows(later *IgnoreRule) bool {
+	if rule.When != "" || rule.hasThreshold() {
+		return false
+	}
+	if rule.Page != later.Page || rule.PageExclude != later.PageExclude {
+		return false
+	}
+	if rule.Status != "" && rule.Status != later.Status || rule.Category != "" && rule.Category != later.Category {
+		return false
+	}
+	if rule.Expires != "" && (later.Expires == "" || rule.Expires < later.Expires) {
+		return false
+	}
+	return isBroaderField(rule.Url, later.Url) && isBroaderField(rule.Error, later.Error)
+}
+
+func (rule *IgnoreRule) isDuplicate(other *IgnoreRule) bool {
+	return rule.Url == other.Url && rule.Error == other.Error && rule.Page == other.Page &&
+		rule.PageExclude == other.PageExclude && rule.Status == other.Status && rule.Category == other.Category &&
+		rule.When == other.When
+}
+
+// isRegexSyntax reports whether the pattern uses regex syntax, other than "." and "?", which are part of most urls.
+func isRegexSyntax(pattern string) bool {
+	return strings.ContainsAny(pattern, `*+^$()[]{}|\`)
+}
+
+func isValidStatusPattern(pattern string) bool {
+	for _, part := range strings.Split(pattern, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if len(part) != 3 || part[0] < '1' || part[0] > '9' || strings.Trim(part[1:], "0123456789x") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// getNeverMatchReason describes why the error pattern cannot match a muffet error, or returns "". Muffet reports http
+// errors as the bare status code, e.g. "404", without the reason phrase.
+func getNeverMatchReason(errorPattern string) string {
+	if statusWithText.MatchString(errorPattern) || strings.Contains(errorPattern, "HTTP") {
+		return fmt.Sprintf("error %q cannot match, muffet reports http errors as the bare status code, e.g. \"404\"", errorPattern)
+	}
+	for code := 100; code < 600; code++ {
+		if text := http.StatusText(code); text != "" && strings.Contains(errorPattern, text) {
+			return fmt.Sprintf("error %q cannot match, muffet reports http errors as the bare status code, e.g. \"%d\"", errorPattern, code)
+		}
+	}
+	return ""
+}
+
+func hasMetadata(rule *IgnoreRule, field string) bool {
+	switch field {
+	case "reason":
+		return rule.Reason != ""
+	case "added":
+		return rule.Added != ""
+	case "expires":
+		return rule.Expires != ""
+	}
+	return true
+}
+
+// lintRule checks a rule on its own.
+func lintRule(rule *IgnoreRule, requireMetadata []string) (findings []lintFinding) {
+	newFinding := func(check, format string, a ...any) {
+		findings = append(findings, lintFinding{rule.source, rule.index, check, fmt.Sprintf(format, a...)})
+	}
+
+	for _, field := range []struct{ name, pattern string }{
+		{"url", rule.Url}, {"error", rule.Error}, {"page", rule.Page}, {"pageExclude", rule.PageExclude},
+	} {
+		if _, err := regexp.Compile(field.pattern); err != nil {
+			newFinding(lintInvalidRegex, "%s %q is not a valid regular expression, it only matches by equality: %v", field.name, field.pattern, err)
+		}
+	}
+
+	switch {
+	case rule.Url == "" || isPatternMatch(rule.Url, broadUrlProbe):
+		newFinding(lintBroad, "url %q matches the urls of any host", rule.Url)
+	case bareHostPattern.MatchString(rule.Url):
+		newFinding(lintBroad, "url %q is a bare host without anchoring, it also matches other hosts and any url containing it, use e.g. %q", rule.Url, hostPattern("https://"+strings.ReplaceAll(rule.Url, `\.`, ".")))
+	}
+
+	// dots in urls without other regex syntax are fine, as such urls also match by equality
+	if match := hostPatternPart.FindStringSubmatch(rule.Url); match != nil && isRegexSyntax(rule.Url) && unescapedDot.MatchString(match[1]) {
+		newFinding(lintUnescapedDot, "url %q has unescaped dots in the host, which match any character, use %q", rule.Url, unescapedDot.ReplaceAllString(match[1], `$1\.`))
+	}
+
+	if reason := getNeverMatchReason(rule.Error); reason != "" {
+		newFinding(lintNeverMatches, "%s", reason)
+	}
+	if rule.Status != "" && !isValidStatusPattern(rule.Status) {
+		newFinding(lintNeverMatches, "status %q cannot match, expected status codes or classes, e.g. \"404\" or \"5xx\"", rule.Status)
+	}
+	if rule.Category != "" && !slices.Contains(errorCategories, errorCategory(rule.Category)) {
+		newFinding(lintNeverMatches, "category %q cannot match, expected one of: %v", rule.Category, errorCategories)
+	}
+
+	for _, field := range requireMetadata {
+		if !hasMetadata(rule, field) {
+			newFinding(lintMissingMetadata, "missing %s", field)
+		}
+	}
+	return
+}
+
+// lintRules checks the rules on their own, and against the earlier rules, which take precedence.
+func lintRules(rules []IgnoreRule, requireMetadata []string) (findings []lintFinding) {
+	for i := range rules {
+		rule := &rules[i]
+		findings = append(findings, lintRule(rule, requireMetadata)...)
+		for j := 0; j < i; j++ {
+			if rules[j].isDuplicate(rule) {
+				findings = append(findings, lintFinding{rule.source, rule.index, lintDuplicate, "duplicate of " + rules[j].origin()})
+				break
+			} else if rules[j].shadows(rule) {
+				findings = append(findings, lintFinding{rule.source, rule.index, lintShadowed, "never used, because the broader " + rules[j].origin() + " matches first"})
+				break
+			}
+		}
+	}
+	return
+}
+
+// runLint checks the ignores files for mistakes, which would otherwise only show up as rules silently not matching.
+// The findings are written as json, and the command fails if there are any, e.g. to run as a pre-commit hook.
+func (c *commandFilter) runLint(ss []string) (bool, error) {
+	args := lintArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&lintArguments{}, lintUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, field := range args.RequireMetadata {
+		if !slices.Contains(metadataFields, field) {
+			return false, fmt.Errorf("invalid metadata field: %s, expected one of: %s", field, strings.Join(metadataFields, ", "))
+		}
+	}
+
+	// files given as arguments, e.g. by pre-commit, are linted like files given via -i
+	loader := newIgnoreLoader(false)
+	ignoreListFiles := append(args.IgnoresJson, remaining...)
+	if len(ignoreListFiles) == 0 {
+		ignoreListFiles = findDefaultIgnoresFiles()
+	}
+	for _, ignoreListFile := range ignoreListFiles {
+		if err = loader.load(ignoreListFile); err != nil {
+			return false, err
+		}
+	}
+
+	findings := lintRules(loader.rules, args.RequireMetadata)
+	if findings == nil {
+		findings = []lintFinding{}
+	}
+	prettyJson, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	c.print(string(prettyJson))
+	return len(findings) == 0, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getLintChecks(findings []lintFinding) (checks []string) {
+	for _, finding := range findings {
+		checks = append(checks, finding.Check)
+	}
+	return
+}
+
+func TestLintRuleValid(t *testing.T) {
+	for _, rule := range []IgnoreRule{
+		{Url: "https://www.raspberrypi.com/products/?variant=8gb", Error: "403"},
+		{Url: `^https://github\.com/my-org/`, Status: "4xx,503"},
+		{Url: literalPattern("https://a.com/x"), Error: "^id #top not found$", Category: "fragment-not-found"},
+	} {
+		assert.Nil(t, lintRule(&rule, nil), "%+v", rule)
+	}
+}
+
+func TestLintRule(t *testing.T) {
+	testCases := []struct {
+		rule     IgnoreRule
+		expected []string
+	}{
+		{IgnoreRule{Url: `^https://a\.com/(`, Error: "404"}, []string{lintInvalidRegex}},
+		{IgnoreRule{Url: ".*", Error: "404"}, []string{lintBroad}},
+		{IgnoreRule{Url: "", Error: "timeout"}, []string{lintBroad}},
+		{IgnoreRule{Url: "github.com", Error: "404"}, []string{lintBroad}},
+		{IgnoreRule{Url: "https://github.com/.*", Error: "404"}, []string{lintUnescapedDot}},
+		{IgnoreRule{Url: "https://a.com/", Error: "404 Not Found"}, []string{lintNeverMatches}},
+		{IgnoreRule{Url: "https://a.com/", Error: "Forbidden"}, []string{lintNeverMatches}},
+		{IgnoreRule{Url: "https://a.com/", Status: "40"}, []string{lintNeverMatches}},
+		{IgnoreRule{Url: "https://a.com/", Category: "slow"}, []string{lintNeverMatches}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, getLintChecks(lintRule(&tc.rule, nil)), "%+v", tc.rule)
+	}
+
+	findings := lintRule(&IgnoreRule{Url: "https://a.com/", Reason: "partner", source: "ignores.json", index: 2}, []string{"reason", "expires"})
+	assert.Equal(t, []lintFinding{{"ignores.json", 2, lintMissingMetadata, "missing expires"}}, findings)
+
+	findings = lintRule(&IgnoreRule{Url: "https://github.com/.*"}, nil)
+	assert.Equal(t, `url "https://github.com/.*" has unescaped dots in the host, which match any character, use "github\\.com"`, findings[0].Message)
+}
+
+func TestLintRulesDuplicateAndShadowed(t *testing.T) {
+	rules := []IgnoreRule{
+		{Url: `^https://a\.com/`, Error: "404", source: "ignores.json"},
+		{Url: `^https://a\.com/`, Error: "404", source: "ignores.json", index: 1},
+		{Url: literalPattern("https://a.com/docs"), Error: "404", source: "ignores.json", index: 2},
+		{Url: literalPattern("https://a.com/docs"), Error: "503", source: "ignores.json", index: 3},
+		// page scoped rules are not shadowed by rules for all pages
+		{Url: literalPattern("https://a.com/docs"), Error: "404", Page: "/blog/", source: "ignores.json", index: 4},
+		// an expiring rule does not shadow a permanent rule
+		{Url: `^https://b\.com/`, Expires: "2026-01-01", source: "ignores.json", index: 5},
+		{Url: `^https://b\.com/x`, source: "ignores.json", index: 6},
+		// rules with a when expression or a threshold do not ignore every link matching their patterns
+		{Url: `^https://c\.com/`, When: "status >= 500", source: "ignores.json", index: 7},
+		{Url: literalPattern("https://c.com/x"), source: "ignores.json", index: 8},
+		{Url: `^https://d\.com/`, MaxOccurrences: 5, source: "ignores.json", index: 9},
+		{Url: literalPattern("https://d.com/x"), source: "ignores.json", index: 10},
+		{Url: `^https://e\.com/`, MaxPages: 2, source: "ignores.json", index: 11},
+		{Url: literalPattern("https://e.com/x"), source: "ignores.json", index: 12},
+	}
+	assert.Equal(t, []lintFinding{
+		{"ignores.json", 1, lintDuplicate, "duplicate of ignores.json rule 0"},
+		{"ignores.json", 2, lintShadowed, "never used, because the broader ignores.json rule 0 matches first"},
+	}, lintRules(rules, nil))
+}
+
+func TestLint(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[
+  {"url": "https://github.com/.*", "error": "404", "reason": "moved repos"},
+  {"url": "https://github.com/my-org/.*", "error": "404"}
+]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"lint", "--require", "reason", ignoreListFile})
+	assert.False(t, ok)
+	assert.Empty(t, stderr.String())
+	var findings []lintFinding
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &findings))
+	assert.Equal(t, []string{lintUnescapedDot, lintUnescapedDot, lintMissingMetadata, lintShadowed}, getLintChecks(findings))
+	assert.Equal(t, 1, findings[3].Rule)
+
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[{"url": "^https://github\\.com/", "error": "404"}]`), 0644))
+	stdout.Reset()
+	ok = cf.Run([]string{"lint", "-i", ignoreListFile})
+	assert.True(t, ok)
+	assert.Equal(t, "[]\n", stdout.String())
+
+	ok = cf.Run([]string{"lint", "--require", "owner", ignoreListFile})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "invalid metadata field: owner, expected one of: reason, added, expires")
+
+	// malformed dates are reported with their position
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[{"url": "^https://github\\.com/", "expires": "31.12.2026"}]`), 0644))
+	ok = cf.Run([]string{"lint", ignoreListFile})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), ignoreListFile+`:1:47: rule 0: invalid expires date "31.12.2026", expected YYYY-MM-DD`)
+
+	ok = cf.Run([]string{"lint", "--help"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "--require")
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// normalizeOptions configure how link and page urls are normalized before they are matched against the ignore rules.
+type normalizeOptions struct {
+	// stripQuery are the names of the query parameters to remove, "*" removes the whole query
+	stripQuery    []string
+	sortQuery     bool
+	stripFragment bool
+}
+
+// getNormalizeOptions returns the normalization options of the arguments, or nil if urls are not normalized.
+// Each of the query and fragment options implies --normalize.
+func (args *arguments) getNormalizeOptions() *normalizeOptions {
+	if !args.Normalize && len(args.StripQuery) == 0 && !args.SortQuery && !args.StripFragment {
+		return nil
+	}
+	return &normalizeOptions{stripQuery: args.StripQuery, sortQuery: args.SortQuery, stripFragment: args.StripFragment}
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// normalizeUrl lowercases the scheme and host, converts IDN hosts to punycode, drops the default port, and
+// normalizes the percent-encoding, so that equivalent urls are written the same way. Urls which cannot be parsed are
+// returned unchanged.
+func (options *normalizeOptions) normalizeUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Opaque != "" {
+		return rawUrl
+	}
+
+	var b strings.Builder
+	if u.Scheme != "" {
+		b.WriteString(u.Scheme + ":")
+	}
+	if u.Host != "" {
+		b.WriteString("//")
+		if u.User != nil {
+			b.WriteString(u.User.String() + "@")
+		}
+		b.WriteString(normalizeHost(u.Hostname()))
+		if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+			b.WriteString(":" + port)
+		}
+	}
+	path := normalizeEncoding(u.EscapedPath())
+	if path == "" && u.Host != "" {
+		path = "/"
+	}
+	b.WriteString(path)
+	if query := options.normalizeQuery(u.RawQuery); query != "" {
+		b.WriteString("?" + query)
+	}
+	if fragment := u.EscapedFragment(); fragment != "" && !options.stripFragment {
+		b.WriteString("#" + normalizeEncoding(fragment))
+	}
+	return b.String()
+}
+
+// normalizeQuery removes the stripped query parameters, and sorts the others by name if requested. The order of
+// parameters with the same name is kept, as it can be significant.
+func (options *normalizeOptions) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" || options.isStrippedQueryParam(param) {
+			continue
+		}
+		params = append(params, normalizeEncoding(param))
+	}
+	if options.sortQuery {
+		sort.SliceStable(params, func(i, j int) bool {
+			return getQueryParamName(params[i]) < getQueryParamName(params[j])
+		})
+	}
+	return strings.Join(params, "&")
+}
+
+func (options *normalizeOptions) isStrippedQueryParam(param string) bool {
+	name := getQueryParamName(param)
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		name = unescaped
+	}
+	for _, stripped := range options.stripQuery {
+		if stripped == "*" || stripped == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getQueryParamName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	return name
+}
+
+// normalizeHost converts the host to its ASCII form, mapped like browsers do (UTS #46): it is lowercased, full-width
+// characters are mapped, and internationalized domain names are converted to punycode. Hosts which are not valid
+// domain names, e.g. with an underscore, are only lowercased.
+func normalizeHost(host string) string {
+	if strings.Contains(host, ":") {
+		// IPv6 address
+		return "[" + strings.ToLower(host) + "]"
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	return strings.ToLower(host)
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// normalizeEncoding decodes percent-encoded unreserved characters, uppercases the hex digits of the other escapes,
+// and escapes non-ASCII bytes and spaces, as recommended by RFC 3986 section 6.2.2.
+func normalizeEncoding(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			decoded := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(decoded) {
+				b.WriteByte(decoded)
+			} else {
+				b.WriteString("%" + strings.ToUpper(s[i+1:i+3]))
+			}
+			i += 2
+		case c >= utf8.RuneSelf || c == ' ':
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&15])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0
+}
+
+// normalize normalizes the urls of the pages and links in the report. Pages which become duplicates are merged, and
+// links which become duplicates on a page are removed.
+func (rep *Report) normalize(options *normalizeOptions) (normalized Report) {
+	pageIndex := map[string]int{}
+	var seenLinks []map[interface{}]bool
+	for _, urlToCheck := range rep.UrlsToCheck {
+		page := options.normalizeUrl(urlToCheck.Url)
+		i, ok := pageIndex[page]
+		if !ok {
+			i = len(normalized.UrlsToCheck)
+			pageIndex[page] = i
+			normalized.UrlsToCheck = append(normalized.UrlsToCheck, UrlToCheck{Url: page, Links: []interface{}{}})
+			seenLinks = append(seenLinks, map[interface{}]bool{})
+		}
+		for _, link := range urlToCheck.Links {
+			switch v := link.(type) {
+			case UrlErrorLink:
+				v.Url = options.normalizeUrl(v.Url)
+				link = v
+			case UrlSuccessLink:
+				v.Url = options.normalizeUrl(v.Url)
+				link = v
+			}
+			if !seenLinks[i][link] {
+				seenLinks[i][link] = true
+				normalized.UrlsToCheck[i].Links = append(normalized.UrlsToCheck[i].Links, link)
+			}
+		}
+	}
+	normalized.Findings = rep.Findings
+	return
+}
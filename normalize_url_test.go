@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUrl(t *testing.T) {
+	options := &normalizeOptions{}
+	for rawUrl, expected := range map[string]string{
+		"HTTPS://Example.COM":                     "https://example.com/",
+		"https://example.com:443/a":               "https://example.com/a",
+		"http://example.com:80/a":                 "http://example.com/a",
+		"http://example.com:8080/a":               "http://example.com:8080/a",
+		"https://example.com/%7euser/a%2fb":       "https://example.com/~user/a%2Fb",
+		"https://example.com/a b":                 "https://example.com/a%20b",
+		"https://münchen.de/bücher":               "https://xn--mnchen-3ya.de/b%C3%BCcher",
+		"https://MÜNCHEN.de/":                     "https://xn--mnchen-3ya.de/",
+		"https://ｅｘａｍｐｌｅ.com/":                    "https://example.com/",
+		"https://日本語.jp/":                         "https://xn--wgv71a119e.jp/",
+		"http://My_Host.local/":                   "http://my_host.local/",
+		"https://[::1]:443/":                      "https://[::1]/",
+		"https://example.com/?b=2&a=1#Top":        "https://example.com/?b=2&a=1#Top",
+		"https://example.com/?":                   "https://example.com/",
+		"mailto:someone@example.com":              "mailto:someone@example.com",
+		"https://user@example.com/":               "https://user@example.com/",
+		"https://example.com/index.html?q=%e2%82": "https://example.com/index.html?q=%E2%82",
+	} {
+		assert.Equal(t, expected, options.normalizeUrl(rawUrl), rawUrl)
+	}
+}
+
+func TestNormalizeUrlQueryAndFragment(t *testing.T) {
+	assert.Equal(t, "https://example.com/index.html?a=1&b=2&b=1",
+		(&normalizeOptions{sortQuery: true, stripFragment: true}).normalizeUrl("https://example.com/index.html?b=2&a=1&b=1#content"))
+	assert.Equal(t, "https://example.com/index.html?page=2",
+		(&normalizeOptions{stripQuery: []string{"lang", "utm_source"}}).normalizeUrl("https://example.com/index.html?lang=en&page=2&utm%5Fsource=x"))
+	assert.Equal(t, "https://example.com/index.html",
+		(&normalizeOptions{stripQuery: []string{"*"}}).normalizeUrl("https://example.com/index.html?lang=en"))
+}
+
+func TestGetNormalizeOptions(t *testing.T) {
+	assert.Nil(t, (&arguments{}).getNormalizeOptions())
+	assert.Equal(t, &normalizeOptions{}, (&arguments{Normalize: true}).getNormalizeOptions())
+	assert.Equal(t, &normalizeOptions{stripQuery: []string{"lang"}}, (&arguments{StripQuery: []string{"lang"}}).getNormalizeOptions())
+}
+
+func TestReportNormalizeMergesPages(t *testing.T) {
+	report, err := (&parseResponse{`[
+  {"url": "https://Example.com/index.html?lang=en", "links": [
+    {"url": "https://partner.com:443/a", "error": "404"}
+  ]},
+  {"url": "https://example.com/other.html", "links": [
+    {"url": "https://example.com/", "status": 200}
+  ]},
+  {"url": "https://example.com/index.html", "links": [
+    {"url": "https://partner.com/a", "error": "404"},
+    {"url": "https://partner.com/b", "error": "404"}
+  ]}
+]`}).loadReport(&arguments{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []UrlToCheck{
+		{Url: "https://example.com/index.html", Links: []interface{}{
+			UrlErrorLink{Url: "https://partner.com/a", Error: "404"},
+			UrlErrorLink{Url: "https://partner.com/b", Error: "404"},
+		}},
+		{Url: "https://example.com/other.html", Links: []interface{}{UrlSuccessLink{Url: "https://example.com/", Status: 200}}},
+	}, report.normalize(&normalizeOptions{stripQuery: []string{"lang"}}).UrlsToCheck)
+}
+
+func TestCommandFilter_Normalize(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	mockExec := &mockMuffetExecutor{result: `[
+  {"url": "https://example.com/", "links": [
+    {"url": "HTTPS://PARTNER.com:443/a", "error": "404"}
+  ]}
+]`}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{executor: mockExec})
+
+	assert.False(t, cf.Run([]string{"--ignore", "https://partner.com/a=404", "http://example.com"}))
+	stdout.Reset()
+	assert.True(t, cf.Run([]string{"--normalize", "--ignore", "https://partner.com/a=404", "http://example.com"}))
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+}
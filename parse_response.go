@@ -7,6 +7,7 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"sync"
 )
 
 func newErrorForMissingField(fieldName, theStruct interface{}) error {
@@ -34,20 +35,34 @@ type UrlErrorLink struct {
 }
 
 func (errorLink *UrlErrorLink) isMatch(linkPatternToIgnore UrlErrorLink) bool {
-	if errorLink.Url != linkPatternToIgnore.Url {
-		// check for regex match on url
-		match, _ := regexp.MatchString(linkPatternToIgnore.Url, errorLink.Url)
-		if !match {
-			return false
-		}
+	if !isPatternMatch(linkPatternToIgnore.Url, errorLink.Url) {
+		return false
 	}
 	// if we got this far, the urls match, so now check the error message
+	return isPatternMatch(linkPatternToIgnore.Error, errorLink.Error)
+}
 
-	if errorLink.Error == linkPatternToIgnore.Error {
+// isPatternMatch reports whether the value equals the pattern, or matches the pattern as a regular expression.
+func isPatternMatch(pattern, value string) bool {
+	if value == pattern {
 		return true
 	}
-	match, _ := regexp.MatchString(linkPatternToIgnore.Error, errorLink.Error)
-	return match
+	r := compilePattern(pattern)
+	return r != nil && r.MatchString(value)
+}
+
+// compiledPatterns caches the regular expressions of patterns, which are matched against every link of a report.
+var compiledPatterns sync.Map
+
+// compilePattern returns the compiled regular expression of a pattern, or nil if the pattern is not a valid regular
+// expression.
+func compilePattern(pattern string) *regexp.Regexp {
+	if r, ok := compiledPatterns.Load(pattern); ok {
+		return r.(*regexp.Regexp)
+	}
+	r, _ := regexp.Compile(pattern)
+	compiledPatterns.Store(pattern, r)
+	return r
 }
 
 func (errorLink *UrlErrorLink) validate() error {
@@ -61,11 +76,31 @@ func (errorLink *UrlErrorLink) validate() error {
 }
 
 type UrlToCheck struct {
-	Url   string        `json:"url"`
-	Links []interface{} `json:"links"`
+	Url string `json:"url"`
+	// Links are UrlSuccessLink or UrlErrorLink values
+	Links []interface{} `json:"links" schema:"link"`
 }
 type Report struct {
 	UrlsToCheck []UrlToCheck
+	Findings    []Finding `json:",omitempty"`
+}
+
+// pageErrorLink is an error link, together with the page on which it appears.
+type pageErrorLink struct {
+	Page string
+	Link UrlErrorLink
+}
+
+// errorLinks returns the error links of all pages in the report.
+func (rep *Report) errorLinks() (links []pageErrorLink) {
+	for _, urlToCheck := range rep.UrlsToCheck {
+		for _, link := range urlToCheck.Links {
+			if errorLink, ok := link.(UrlErrorLink); ok {
+				links = append(links, pageErrorLink{urlToCheck.Url, errorLink})
+			}
+		}
+	}
+	return
 }
 
 //goland:noinspection SpellCheckingInspection
@@ -145,17 +180,18 @@ func (r *parseResponse) loadReport(args *arguments) (report Report, err error) {
 	return
 }
 
-func (rep *Report) filter(errorsToIgnore []UrlErrorLink, isVerbose bool) (filteredReport Report, err error) {
+func (rep *Report) filter(errorsToIgnore []IgnoreRule, isVerbose bool) (filteredReport Report, err error) {
+	exceeded, findings := rep.getExceededRules(errorsToIgnore)
 	var tempUrlsToCheck []UrlToCheck
 	for _, urlToCheck := range rep.UrlsToCheck {
 		tempUrlToCheck := UrlToCheck{Url: urlToCheck.Url}
 		for _, link := range urlToCheck.Links {
 			switch v := link.(type) {
 			case UrlErrorLink:
-				if !isErrorIgnored(v, errorsToIgnore) {
+				if rule := findMatchingRule(urlToCheck.Url, v, errorsToIgnore); rule == nil || exceeded[rule] {
 					tempUrlToCheck.Links = append(tempUrlToCheck.Links, link)
 				} else if isVerbose {
-					fmt.Printf("skipping urlError: %+v on UrlToCheck: %s\n", link, urlToCheck.Url)
+					fmt.Printf("skipping urlError: %+v on UrlToCheck: %s, by %s\n", link, urlToCheck.Url, rule.origin())
 				}
 			case UrlSuccessLink:
 				// do nothing here, as we leave success links alone for now
@@ -171,17 +207,22 @@ func (rep *Report) filter(errorsToIgnore []UrlErrorLink, isVerbose bool) (filter
 			tempUrlsToCheck = append(tempUrlsToCheck, tempUrlToCheck)
 		}
 	}
-	filteredReport = Report{UrlsToCheck: tempUrlsToCheck}
+	filteredReport = Report{UrlsToCheck: tempUrlsToCheck, Findings: findings}
 	return
 }
 
-func isErrorIgnored(urlError UrlErrorLink, errorsToIgnore []UrlErrorLink) bool {
-	for _, errToIgnore := range errorsToIgnore {
-		if urlError.isMatch(errToIgnore) {
-			return true
+func isErrorIgnored(page string, urlError UrlErrorLink, errorsToIgnore []IgnoreRule) bool {
+	return findMatchingRule(page, urlError, errorsToIgnore) != nil
+}
+
+// findMatchingRule returns the first rule ignoring the error link, or nil.
+func findMatchingRule(page string, urlError UrlErrorLink, errorsToIgnore []IgnoreRule) *IgnoreRule {
+	for i := range errorsToIgnore {
+		if errorsToIgnore[i].isMatch(page, urlError) {
+			return &errorsToIgnore[i]
 		}
 	}
-	return false
+	return nil
 }
 
 func doesFileExist(fileToCheck string) (itExists bool, err error) {
@@ -194,40 +235,72 @@ func doesFileExist(fileToCheck string) (itExists bool, err error) {
 	return
 }
 
-func loadIgnoreList(args *arguments) (ignoreUrlErrors []UrlErrorLink, err error) {
-	var ignoreListFile string
-	if args.IgnoresJson != "" {
-		ignoreListFile = args.IgnoresJson
-		var itExists bool
-		if itExists, err = doesFileExist(ignoreListFile); !itExists {
-			// a non-default file was specified, so it is an error if that specified file is missing
-			return
+func loadIgnoreList(args *arguments) (ignoreUrlErrors []IgnoreRule, err error) {
+	ignores, err := loadIgnores(args)
+	return ignores.Rules, err
+}
+
+// loadIgnores loads the rules and expectations of the ignores files, and the ad hoc rules, and resolves their patterns
+// against the url of the checked website.
+func loadIgnores(args *arguments) (ignores ignoreFile, err error) {
+	if ignores, err = loadIgnoreFiles(args); err != nil {
+		return
+	}
+	err = resolveSitePatterns(&ignores, args)
+	return
+}
+
+// loadIgnoresIfSiteKnown is loadIgnores for commands, which also work without the url of the checked website: the
+// site patterns are then kept as written in the ignores files.
+func loadIgnoresIfSiteKnown(args *arguments) (ignores ignoreFile, err error) {
+	if ignores, err = loadIgnoreFiles(args); err != nil || !hasSiteUrl(args) {
+		return
+	}
+	err = resolveSitePatterns(&ignores, args)
+	return
+}
+
+// loadIgnoreFiles loads the rules and expectations of the ignores files, and the ad hoc rules, as written.
+func loadIgnoreFiles(args *arguments) (ignores ignoreFile, err error) {
+	// ad hoc rules from the command line and the environment take precedence over the ignores files
+	var adHocRules []IgnoreRule
+	if adHocRules, err = loadAdHocRules(args); err != nil {
+		return
+	}
+
+	var ignoreListFiles []string
+	if len(args.IgnoresJson) > 0 {
+		for _, ignoreListFile := range args.IgnoresJson {
+			if isRemoteIgnoreSource(ignoreListFile) {
+				ignoreListFiles = append(ignoreListFiles, ignoreListFile)
+				continue
+			}
+			var itExists bool
+			if itExists, err = doesFileExist(ignoreListFile); !itExists && ignoreListFile == args.newIgnoresFile {
+				// the file to edit is created when the first rule is added, until then it has no rules
+				err = nil
+				continue
+			} else if !itExists {
+				// a non-default file was specified, so it is an error if that specified file is missing
+				return
+			}
+			ignoreListFiles = append(ignoreListFiles, ignoreListFile)
 		}
 	} else {
-		// next, we look for ignores file in the current working directory
-		pwd, _ := os.Getwd()
-		ignoreListFile = getDefaultIgnoresFile(pwd)
-		var itExists bool
-		if itExists, _ = doesFileExist(ignoreListFile); !itExists {
-			// check user home dir for ignores file
-			homeDir, _ := getUserHomeDir()
-			ignoreListFile = getDefaultIgnoresFile(homeDir)
+		// look for ignores files in the project directories, the user home dir and the org-wide location
+		ignoreListFiles = findDefaultIgnoresFiles()
+		if len(ignoreListFiles) == 0 && args.Verbose {
+			fmt.Printf("no ignores file found, default: %s\n", defaultIgnoresSuffix)
 		}
 	}
 
-	var ignoreListRaw []byte
-	ignoreListRaw, err = os.ReadFile(ignoreListFile)
-	if err != nil {
-		if args.Verbose {
-			fmt.Printf("ignoring missing ignores file: %s", ignoreListFile)
+	loader := newIgnoreLoader(args.Verbose)
+	for _, ignoreListFile := range ignoreListFiles {
+		if err = loader.load(ignoreListFile); err != nil {
+			return
 		}
-		err = nil
-		return
-	}
-
-	err = json.Unmarshal(ignoreListRaw, &ignoreUrlErrors)
-	if err != nil {
-		fmt.Printf("error loading ignore list file: %s, error: %v", ignoreListFile, err)
 	}
+	ignores.Rules = append(adHocRules, loader.rules...)
+	ignores.Expectations = loader.expectations
 	return
 }
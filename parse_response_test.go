@@ -279,13 +279,13 @@ func TestUrlErrorIsMatchPatternInUrlParam(t *testing.T) {
 }
 
 func TestLoadIgnoreListFromTestdata(t *testing.T) {
-	args := arguments{IgnoresJson: "testdata/urlErrorIgnore.json"}
+	args := arguments{IgnoresJson: []string{"testdata/urlErrorIgnore.json"}}
 	ignores, err := loadIgnoreList(&args)
 	assert.Nil(t, err)
 	assert.NotNil(t, ignores)
 }
 func TestLoadIgnoreListBadArg(t *testing.T) {
-	args := arguments{IgnoresJson: "bad-ignore-file.json"}
+	args := arguments{IgnoresJson: []string{"bad-ignore-file.json"}}
 	ignores, err := loadIgnoreList(&args)
 	assert.EqualError(t, err, "stat bad-ignore-file.json: no such file or directory")
 	assert.Nil(t, ignores)
@@ -335,7 +335,7 @@ func TestLoadIgnoreListInvalidDefault(t *testing.T) {
 
 	args := arguments{Verbose: true}
 	ignores, err := loadIgnoreList(&args)
-	assert.EqualError(t, err, "json: cannot unmarshal string into Go value of type []main.UrlErrorLink")
+	assert.EqualError(t, err, getDefaultIgnoresFile(userHome)+":1:1: expected a list of rules, or an object with rules")
 	assert.Nil(t, ignores)
 }
 
@@ -355,7 +355,7 @@ func TestReportFilterOneErrorMatch(t *testing.T) {
 	report, err := resp.loadReport(&arguments{})
 	assert.Nil(t, err)
 
-	reportFiltered, err := report.filter([]UrlErrorLink{
+	reportFiltered, err := report.filter([]IgnoreRule{
 		{Url: "https://help.sonatype.com/index.html#content-wrapper", Error: "id #content-wrapper not found"},
 	}, false)
 	assert.Nil(t, err)
@@ -370,7 +370,7 @@ func TestReportFilterTwoErrorMatch(t *testing.T) {
 	keptErrLink := UrlErrorLink{"urlNoMatch", "errorNoMatch"}
 	report.UrlsToCheck[0].Links = append(report.UrlsToCheck[0].Links, keptErrLink)
 
-	reportFiltered, err := report.filter([]UrlErrorLink{
+	reportFiltered, err := report.filter([]IgnoreRule{
 		{Url: "https://help.sonatype.com/index.html#content-wrapper", Error: "id #content-wrapper not found"},
 	}, false)
 	//goland:noinspection GoDfaErrorMayBeNotNil
@@ -387,7 +387,7 @@ func TestReportFilterErrorMatchAndSuccessLink(t *testing.T) {
 	keptSuccessLink := UrlSuccessLink{"urlSuccess", 200}
 	report.UrlsToCheck[0].Links = append(report.UrlsToCheck[0].Links, keptSuccessLink)
 
-	reportFiltered, err := report.filter([]UrlErrorLink{
+	reportFiltered, err := report.filter([]IgnoreRule{
 		{Url: "https://help.sonatype.com/index.html#content-wrapper", Error: "id #content-wrapper not found"},
 	}, false)
 	assert.Nil(t, err)
@@ -406,3 +406,40 @@ func TestReportFilterUnknownLinkInterface(t *testing.T) {
 	_, err = report.filter(nil, false)
 	assert.EqualError(t, err, "unexpected url error type string")
 }
+
+const jsonReportSameErrorTwoPages = `[
+  {
+    "url": "https://help.sonatype.com/search.html",
+    "links": [
+      {
+        "url": "https://help.sonatype.com/index.html#content-wrapper",
+        "error": "id #content-wrapper not found"
+      }
+    ]
+  },
+  ` + jsonUrlErrorLInk + `
+]`
+
+func TestReportFilterPageScopedMatch(t *testing.T) {
+	resp := parseResponse{jsonReportSameErrorTwoPages}
+	report, err := resp.loadReport(&arguments{})
+	assert.Nil(t, err)
+
+	reportFiltered, err := report.filter([]IgnoreRule{
+		{Url: urlErrorLinkUrl, Error: urlErrorLinkError, Page: "https://help.sonatype.com/search.html"},
+	}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, Report{UrlsToCheck: []UrlToCheck{expectedFirstUrlToCheckError}}, reportFiltered)
+}
+func TestReportFilterPageExcludeMatch(t *testing.T) {
+	resp := parseResponse{jsonReportSameErrorTwoPages}
+	report, err := resp.loadReport(&arguments{})
+	assert.Nil(t, err)
+
+	reportFiltered, err := report.filter([]IgnoreRule{
+		{Url: urlErrorLinkUrl, Error: urlErrorLinkError, PageExclude: "/search\\.html$"},
+	}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reportFiltered.UrlsToCheck))
+	assert.Equal(t, "https://help.sonatype.com/search.html", reportFiltered.UrlsToCheck[0].Url)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// siteUrlVariable is replaced by the url of the checked website, so one ignores file can serve staging and production.
+const siteUrlVariable = "SITE_URL"
+
+// siteUrlReference is kept in the patterns, when loading an ignores file, until the url of the checked website is known.
+const siteUrlReference = "${" + siteUrlVariable + "}"
+
+var (
+	definitionReference = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*}}`)
+	variableReference   = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+)
+
+// lookupPatternVariable returns the value of a ${VAR} variable: the url of the checked website for ${SITE_URL}, or
+// the environment variable.
+func lookupPatternVariable(name, siteUrl string) (string, bool) {
+	if name == siteUrlVariable && siteUrl != "" {
+		return siteUrl, true
+	}
+	return os.LookupEnv(name)
+}
+
+// expandIgnoreDocument expands the patterns of the rules and expectations of an ignores file in place. {{name}} is
+// replaced by the named pattern of the definitions of the file, and ${VAR} by the value of the variable, which is
+// matched literally. ${SITE_URL} is resolved later by resolveSiteUrlReferences, as only a check knows the website.
+func expandIgnoreDocument(ignoreListFile string, doc *yaml.Node) error {
+	if doc.Kind == yaml.DocumentNode {
+		doc = doc.Content[0]
+	}
+
+	definitions := map[string]string{}
+	var listNodes []*yaml.Node
+	switch doc.Kind {
+	case yaml.SequenceNode:
+		listNodes = append(listNodes, doc)
+	case yaml.MappingNode:
+		for i := 0; i < len(doc.Content); i += 2 {
+			key, value := doc.Content[i], doc.Content[i+1]
+			switch key.Value {
+			case "definitions":
+				if err := value.Decode(&definitions); err != nil {
+					return newIgnoreFileError(ignoreListFile, value, "invalid definitions: %v", yamlErrorMessage(err))
+				}
+			case "rules", "expectations":
+				listNodes = append(listNodes, value)
+			}
+		}
+	}
+
+	for _, listNode := range listNodes {
+		for _, entry := range listNode.Content {
+			if entry.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i < len(entry.Content); i += 2 {
+				key, value := entry.Content[i], entry.Content[i+1]
+				if !slices.Contains(patternFields, key.Value) || value.Kind != yaml.ScalarNode {
+					continue
+				}
+				expanded, err := expandPattern(ignoreListFile, value, definitions)
+				if err != nil {
+					return err
+				}
+				value.Value = expanded
+			}
+		}
+	}
+	return nil
+}
+
+// expandPattern expands the definitions first, so that definitions can contain variables.
+func expandPattern(ignoreListFile string, value *yaml.Node, definitions map[string]string) (string, error) {
+	var err error
+	pattern := definitionReference.ReplaceAllStringFunc(value.Value, func(reference string) string {
+		name := definitionReference.FindStringSubmatch(reference)[1]
+		definition, ok := definitions[name]
+		if !ok && err == nil {
+			err = newIgnoreFileError(ignoreListFile, value, "unknown definition {{%s}}", name)
+		}
+		return definition
+	})
+	pattern = variableReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+		name := variableReference.FindStringSubmatch(reference)[1]
+		if name == siteUrlVariable {
+			return reference
+		}
+		variable, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = newIgnoreFileError(ignoreListFile, value, "unresolved variable ${%s}", name)
+		}
+		return regexp.QuoteMeta(variable)
+	})
+	return pattern, err
+}
+
+// resolveSiteUrlReferences replaces ${SITE_URL} in the patterns of the rules and expectations by the url of the checked
+// website, or $SITE_URL when reading a report via --input-json. A trailing "/" is removed, so it can be followed by a
+// path.
+func resolveSiteUrlReferences(ignores *ignoreFile, args *arguments) error {
+	siteUrl, _ := lookupPatternVariable(siteUrlVariable, args.URL)
+	resolve := func(origin string, patterns ...*string) error {
+		for _, pattern := range patterns {
+			if !strings.Contains(*pattern, siteUrlReference) {
+				continue
+			} else if siteUrl == "" {
+				return fmt.Errorf("%s: unresolved variable %s, it needs the url of the website to check", origin, siteUrlReference)
+			}
+			*pattern = strings.ReplaceAll(*pattern, siteUrlReference, regexp.QuoteMeta(strings.TrimSuffix(siteUrl, "/")))
+		}
+		return nil
+	}
+
+	for i := range ignores.Rules {
+		rule := &ignores.Rules[i]
+		if err := resolve(rule.origin(), &rule.Url, &rule.Error, &rule.Page, &rule.PageExclude); err != nil {
+			return err
+		}
+	}
+	for i := range ignores.Expectations {
+		expectation := &ignores.Expectations[i]
+		if err := resolve(fmt.Sprintf("expectation %d", i), &expectation.Url, &expectation.Page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ignoresWithDefinitions = `
+definitions:
+  cdn: ^https://(cdn|static)\d*\.example\.com/
+  site: ${SITE_URL}
+rules:
+  - url: "{{cdn}}fonts/"
+    error: "404"
+  - url: ^{{ site }}/blog/
+    error: ${BLOG_ERROR}
+expectations:
+  - url: ${SITE_URL}/pricing
+`
+
+func TestLoadIgnoresExpandsPatterns(t *testing.T) {
+	t.Setenv("BLOG_ERROR", "5..")
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(ignoresWithDefinitions), 0644))
+
+	ignores, err := loadIgnores(&arguments{IgnoresJson: []string{ignoreListFile}, URL: "https://staging.my-site.com/"})
+	assert.Nil(t, err)
+	assert.Equal(t, `^https://(cdn|static)\d*\.example\.com/fonts/`, ignores.Rules[0].Url)
+	assert.Equal(t, `^https://staging\.my-site\.com/blog/`, ignores.Rules[1].Url)
+	assert.Equal(t, `5\.\.`, ignores.Rules[1].Error)
+	assert.Equal(t, `https://staging\.my-site\.com/pricing`, ignores.Expectations[0].Url)
+	assert.True(t, isPatternMatch(ignores.Rules[1].Url, "https://staging.my-site.com/blog/post"))
+}
+
+func TestLoadIgnoresSiteUrlFromEnvironment(t *testing.T) {
+	t.Setenv("SITE_URL", "https://my-site.com")
+	t.Setenv("BLOG_ERROR", "503")
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(ignoresWithDefinitions), 0644))
+
+	ignores, err := loadIgnores(&arguments{IgnoresJson: []string{ignoreListFile}, MuffetJson: "report.json"})
+	assert.Nil(t, err)
+	assert.Equal(t, `^https://my-site\.com/blog/`, ignores.Rules[1].Url)
+}
+
+func TestLoadIgnoresUnresolvedPatterns(t *testing.T) {
+	for _, tc := range []struct{ content, expected string }{
+		{ignoresWithDefinitions, "ignores.yaml:9:12: unresolved variable ${BLOG_ERROR}"},
+		{`[{"url": "{{cdn}}fonts/"}]`, "ignores.json:1:10: unknown definition {{cdn}}"},
+		{`{"definitions": ["a"], "rules": []}`, "ignores.json:1:17: invalid definitions: cannot unmarshal !!seq into map[string]string"},
+	} {
+		ignoreListFile := "ignores.yaml"
+		if tc.content[0] != '\n' {
+			ignoreListFile = "ignores.json"
+		}
+		path := filepath.Join(t.TempDir(), ignoreListFile)
+		assert.Nil(t, os.WriteFile(path, []byte(tc.content), 0644))
+
+		_, err := loadIgnores(&arguments{IgnoresJson: []string{path}, URL: "https://my-site.com/"})
+		assert.EqualError(t, err, filepath.Dir(path)+"/"+tc.expected)
+	}
+}
+
+func TestParseAdHocRulesRejectsDefinitions(t *testing.T) {
+	_, err := parseAdHocRules("$MUFFET_FILTER_IGNORES", `{"definitions": {"cdn": "cdn"}, "rules": [{"url": "{{cdn}}"}]}`)
+	assert.EqualError(t, err, "$MUFFET_FILTER_IGNORES: definitions are only supported in ignores files")
+}
+
+func TestLoadIgnoresWithoutSiteUrl(t *testing.T) {
+	t.Setenv(siteUrlVariable, "")
+	t.Setenv("BLOG_ERROR", "503")
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(ignoresWithDefinitions), 0644))
+
+	_, err := loadIgnores(&arguments{IgnoresJson: []string{ignoreListFile}, MuffetJson: "report.json"})
+	assert.EqualError(t, err, ignoreListFile+" rule 1: unresolved variable ${SITE_URL}, it needs the url of the website to check")
+
+	// lint only loads the ignores file, so ${SITE_URL} stays in the pattern
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+	assert.True(t, cf.Run([]string{"lint", ignoreListFile}))
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, "[]\n", stdout.String())
+}
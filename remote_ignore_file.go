@@ -0,0 +1,175 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// userCacheDir is a variable, so tests can use a temp dir for cached ignores files.
+var userCacheDir = os.UserCacheDir
+
+var remoteIgnoresClient = &http.Client{Timeout: 30 * time.Second}
+
+// a remote ignores file can be pinned to a checksum, e.g. https://example.com/ignores.json#sha256=<hex digest>
+const sha256Pin = "sha256="
+
+func isRemoteIgnoreSource(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// cachedIgnoreFileMeta holds the validators of a cached remote ignores file.
+type cachedIgnoreFileMeta struct {
+	Url          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// fetchIgnoreFile downloads a remote ignores file. The file is cached in the user cache dir and revalidated
+// with ETag and If-Modified-Since. If the download fails, the cached copy is used.
+func fetchIgnoreFile(source string, isVerbose bool) (data []byte, err error) {
+	fileUrl, pin, _ := strings.Cut(source, "#")
+	if pin != "" && !strings.HasPrefix(pin, sha256Pin) {
+		return nil, fmt.Errorf("unsupported pin: %s, expected #%s<hex digest>", pin, sha256Pin)
+	}
+	pin = strings.ToLower(strings.TrimPrefix(pin, sha256Pin))
+
+	cacheFile, err := getIgnoreCacheFile(fileUrl)
+	if err != nil {
+		return
+	}
+	var meta cachedIgnoreFileMeta
+	cached, cacheErr := os.ReadFile(cacheFile)
+	if cacheErr == nil {
+		if metaRaw, metaErr := os.ReadFile(cacheFile + ".meta"); metaErr == nil {
+			_ = json.Unmarshal(metaRaw, &meta)
+		}
+	}
+
+	data, meta, err = downloadIgnoreFile(fileUrl, cached, meta)
+	if err == nil {
+		err = verifyPin(fileUrl, data, pin)
+	}
+	if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+		if pinErr := verifyPin(fileUrl, cached, pin); pinErr != nil {
+			return nil, pinErr
+		}
+		log.Printf("could not fetch ignores file: %s, using cached copy: %s, error: %v", fileUrl, cacheFile, err)
+		return cached, nil
+	}
+	if isVerbose {
+		fmt.Printf("fetched ignores file: %s, cached as: %s\n", fileUrl, cacheFile)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return
+	}
+	if err = os.WriteFile(cacheFile, data, 0644); err != nil {
+		return
+	}
+	metaRaw, _ := json.Marshal(meta)
+	err = os.WriteFile(cacheFile+".meta", metaRaw, 0644)
+	return
+}
+
+func downloadIgnoreFile(fileUrl string, cached []byte, meta cachedIgnoreFileMeta) (data []byte, newMeta cachedIgnoreFileMeta, err error) {
+	req, err := http.NewRequest(http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return
+	}
+	if cached != nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	resp, err := remoteIgnoresClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, meta, nil
+	case resp.StatusCode != http.StatusOK:
+		err = fmt.Errorf("unexpected status fetching %s: %s", fileUrl, resp.Status)
+		return
+	}
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	newMeta = cachedIgnoreFileMeta{Url: fileUrl, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	return
+}
+
+func verifyPin(fileUrl string, data []byte, pin string) error {
+	if pin == "" {
+		return nil
+	}
+	digest := sha256.Sum256(data)
+	if actual := hex.EncodeToString(digest[:]); actual != pin {
+		return fmt.Errorf("checksum mismatch for %s, expected sha256: %s, actual: %s", fileUrl, pin, actual)
+	}
+	return nil
+}
+
+// getIgnoreCacheFile returns the cache file of a remote ignores file, named by the hash of its url.
+func getIgnoreCacheFile(fileUrl string) (string, error) {
+	cacheDir, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(fileUrl))
+	u, err := url.Parse(fileUrl)
+	if err != nil {
+		return "", err
+	}
+	// keep the extension, which determines the format of the file
+	return filepath.Join(cacheDir, agentName, "ignores", hex.EncodeToString(digest[:])+filepath.Ext(u.Path)), nil
+}
+
+// resolveIgnoreSource resolves an include relative to the ignores file that includes it.
+func resolveIgnoreSource(includingSource, include string) (string, error) {
+	if isRemoteIgnoreSource(include) || filepath.IsAbs(include) {
+		return include, nil
+	}
+	if isRemoteIgnoreSource(includingSource) {
+		base, err := url.Parse(includingSource)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(include)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	return filepath.Join(filepath.Dir(includingSource), include), nil
+}
+
+// getIgnoreSourcePath returns the path of an ignores file, or the url path of a remote ignores file.
+func getIgnoreSourcePath(source string) string {
+	if isRemoteIgnoreSource(source) {
+		if u, err := url.Parse(source); err == nil {
+			return u.Path
+		}
+	}
+	return source
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const remoteIgnoresContent = `{
+  "include": ["shared.yaml"],
+  "rules": [{"url": "https://www.linkedin.com/.*", "error": "999"}]
+}`
+
+const remoteSharedContent = `- url: https://cdn.example.com/
+  status: "429"
+`
+
+type remoteIgnoresServer struct {
+	*httptest.Server
+	requests, notModified int
+	down                  bool
+}
+
+func newRemoteIgnoresServer(t *testing.T) *remoteIgnoresServer {
+	s := &remoteIgnoresServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		if s.down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		content := map[string]string{"/config/ignores.json": remoteIgnoresContent, "/config/shared.yaml": remoteSharedContent}[r.URL.Path]
+		if content == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		etag := `"` + r.URL.Path + `-v1"`
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(s.Close)
+
+	origUserCacheDir := userCacheDir
+	cacheDir := t.TempDir()
+	userCacheDir = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { userCacheDir = origUserCacheDir })
+	return s
+}
+
+func getExpectedRemoteRules(serverUrl string) []IgnoreRule {
+	return []IgnoreRule{
+		{Url: "https://www.linkedin.com/.*", Error: "999", source: serverUrl + "/config/ignores.json"},
+		{Url: "https://cdn.example.com/", Status: "429", source: serverUrl + "/config/shared.yaml"},
+	}
+}
+
+func TestLoadIgnoreListRemote(t *testing.T) {
+	server := newRemoteIgnoresServer(t)
+	args := arguments{IgnoresJson: []string{server.URL + "/config/ignores.json"}, Verbose: true}
+	expectedRemoteRules := getExpectedRemoteRules(server.URL)
+
+	ignores, err := loadIgnoreList(&args)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedRemoteRules, ignores)
+	assert.Equal(t, 2, server.requests)
+	assert.Equal(t, 0, server.notModified)
+
+	// cached files are revalidated with their etag
+	ignores, err = loadIgnoreList(&args)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedRemoteRules, ignores)
+	assert.Equal(t, 4, server.requests)
+	assert.Equal(t, 2, server.notModified)
+
+	// the cached files are used when the server fails
+	server.down = true
+	ignores, err = loadIgnoreList(&args)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedRemoteRules, ignores)
+}
+
+func TestLoadIgnoreListRemoteNotCached(t *testing.T) {
+	server := newRemoteIgnoresServer(t)
+	server.down = true
+
+	_, err := loadIgnoreList(&arguments{IgnoresJson: []string{server.URL + "/config/ignores.json"}})
+	assert.EqualError(t, err, "unexpected status fetching "+server.URL+"/config/ignores.json: 503 Service Unavailable")
+}
+
+func TestFetchIgnoreFilePinned(t *testing.T) {
+	server := newRemoteIgnoresServer(t)
+	fileUrl := server.URL + "/config/shared.yaml"
+	digest := sha256.Sum256([]byte(remoteSharedContent))
+	pin := hex.EncodeToString(digest[:])
+
+	data, err := fetchIgnoreFile(fileUrl+"#sha256="+pin, false)
+	assert.Nil(t, err)
+	assert.Equal(t, remoteSharedContent, string(data))
+
+	badPin := hex.EncodeToString(make([]byte, sha256.Size))
+	_, err = fetchIgnoreFile(fileUrl+"#sha256="+badPin, false)
+	assert.EqualError(t, err, "checksum mismatch for "+fileUrl+", expected sha256: "+badPin+", actual: "+pin)
+
+	_, err = fetchIgnoreFile(fileUrl+"#md5=abc", false)
+	assert.EqualError(t, err, "unsupported pin: md5=abc, expected #sha256=<hex digest>")
+}
+
+func TestResolveIgnoreSource(t *testing.T) {
+	for _, tc := range []struct {
+		including, include, expected string
+	}{
+		{"dir/ignores.json", "shared.json", "dir/shared.json"},
+		{"dir/ignores.json", "/abs/shared.json", "/abs/shared.json"},
+		{"dir/ignores.json", "https://example.com/shared.json", "https://example.com/shared.json"},
+		{"https://example.com/config/ignores.json", "shared.json", "https://example.com/config/shared.json"},
+		{"https://example.com/config/ignores.json", "../other/shared.json", "https://example.com/other/shared.json"},
+	} {
+		resolved, err := resolveIgnoreSource(tc.including, tc.include)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, resolved)
+	}
+}
+
+func TestGetIgnoreFileFormatRemote(t *testing.T) {
+	assert.Equal(t, formatYaml, getIgnoreFileFormat("https://example.com/ignores.yaml?ref=main"))
+	assert.Equal(t, formatJsonc, getIgnoreFileFormat("https://example.com/ignores.json#sha256=abc"))
+}
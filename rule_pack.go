@@ -0,0 +1,120 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rulePackFiles are the curated rule packs for well-known hosts, which block link checkers. To update a pack, change
+// its file in the packs directory, and increase its version.
+//
+//go:embed packs/*.yaml
+var rulePackFiles embed.FS
+
+const rulePackDir = "packs"
+
+// rulePackSourcePrefix marks the source of rules from a rule pack, e.g. "pack:social-media@1".
+const rulePackSourcePrefix = "pack:"
+
+type rulePack struct {
+	name        string
+	version     int
+	description string
+	rules       []IgnoreRule
+}
+
+func (pack *rulePack) source() string {
+	return fmt.Sprintf("%s%s@%d", rulePackSourcePrefix, pack.name, pack.version)
+}
+
+// getRulePackNames returns the names of the embedded rule packs.
+func getRulePackNames() (names []string) {
+	entries, _ := rulePackFiles.ReadDir(rulePackDir)
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	return
+}
+
+// loadRulePack decodes an embedded rule pack. A pack is an ignores file with rules, and its version and description.
+func loadRulePack(name string) (pack rulePack, err error) {
+	file := path.Join(rulePackDir, name+".yaml")
+	data, err := rulePackFiles.ReadFile(file)
+	if err != nil || strings.ContainsAny(name, "/\\") {
+		return pack, fmt.Errorf("unknown rule pack %q, expected one of: %s", name, strings.Join(getRulePackNames(), ", "))
+	}
+	doc, err := parseIgnoreDocument(file, data)
+	if err != nil {
+		return
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return pack, newIgnoreFileError(file, root, "expected an object with version, description and rules")
+	}
+
+	pack.name = name
+	rest := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "version":
+			if pack.version, err = strconv.Atoi(value.Value); err != nil || pack.version < 1 {
+				return pack, newIgnoreFileError(file, value, "invalid version %q, expected a positive number", value.Value)
+			}
+		case "description":
+			pack.description = value.Value
+		default:
+			rest.Content = append(rest.Content, key, value)
+		}
+	}
+	ignores, err := decodeIgnoreFile(file, rest)
+	if err != nil {
+		return
+	} else if len(ignores.Include) > 0 || len(ignores.Use) > 0 || len(ignores.Expectations) > 0 {
+		return pack, fmt.Errorf("%s: rule packs only contain rules", file)
+	}
+	for i, rule := range ignores.Rules {
+		rule.source, rule.index = pack.source(), i
+		pack.rules = append(pack.rules, rule)
+	}
+	return
+}
+
+// parseRulePackUse parses the name of a rule pack to use, optionally with the expected version, e.g. "social-media@1".
+func parseRulePackUse(use string) (name string, version int, err error) {
+	name, versionText, hasVersion := strings.Cut(use, "@")
+	if hasVersion {
+		if version, err = strconv.Atoi(versionText); err != nil {
+			return "", 0, fmt.Errorf("invalid rule pack version: %s, expected e.g. %s@1", use, name)
+		}
+	}
+	return
+}
+
+// loadPack adds the rules of a rule pack, unless the pack is already used by another ignores file.
+func (l *ignoreLoader) loadPack(use string) error {
+	name, version, err := parseRulePackUse(use)
+	if err != nil {
+		return err
+	}
+	pack, err := loadRulePack(name)
+	if err != nil {
+		return err
+	} else if version != 0 && version != pack.version {
+		return fmt.Errorf("rule pack %s is version %d, expected: %d", name, pack.version, version)
+	}
+	if l.loaded[pack.source()] {
+		return nil
+	}
+	l.loaded[pack.source()] = true
+	if l.isVerbose {
+		fmt.Printf("loaded rule pack: %s, rules: %d\n", pack.source(), len(pack.rules))
+	}
+	l.rules = append(l.rules, pack.rules...)
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRulePacks(t *testing.T) {
+	names := getRulePackNames()
+	assert.Equal(t, []string{"bot-protection", "rate-limited-cdns", "social-media"}, names)
+	for _, name := range names {
+		pack, err := loadRulePack(name)
+		assert.Nil(t, err, name)
+		assert.Equal(t, name, pack.name)
+		assert.Greater(t, pack.version, 0, name)
+		assert.NotEmpty(t, pack.description, name)
+		assert.NotEmpty(t, pack.rules, name)
+		for _, rule := range pack.rules {
+			assert.NotNil(t, compilePattern(rule.Url), rule.Url)
+			assert.NotEmpty(t, rule.Reason, rule.Url)
+		}
+	}
+
+	_, err := loadRulePack("no-such-pack")
+	assert.EqualError(t, err, `unknown rule pack "no-such-pack", expected one of: bot-protection, rate-limited-cdns, social-media`)
+}
+
+func TestLoadIgnoresWithRulePacks(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	dir := t.TempDir()
+	ignoreListFile := filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`{"use": ["social-media@1"], "rules": [{"url": "https://partner.com/"}]}`), 0644))
+	shared := filepath.Join(dir, "shared.json")
+	assert.Nil(t, os.WriteFile(shared, []byte(`{"use": ["social-media"], "include": ["ignores.json"]}`), 0644))
+
+	ignores, err := loadIgnores(&arguments{IgnoresJson: []string{shared}})
+	assert.Nil(t, err)
+	// the pack is only added once
+	pack, _ := loadRulePack("social-media")
+	assert.Equal(t, len(pack.rules)+1, len(ignores.Rules))
+	assert.Equal(t, "pack:social-media@1 rule 0", ignores.Rules[0].origin())
+
+	explanation := explainLink(ignores.Rules, nil, "https://my-site.com/", UrlErrorLink{Url: "https://www.linkedin.com/in/someone", Error: "999"})
+	assert.Contains(t, explanation, "decision: ignored by pack:social-media@1 rule 0")
+}
+
+func TestLoadIgnoresRulePackErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		`{"use": ["social-media@7"]}`: "rule pack social-media is version 1, expected: 7",
+		`{"use": ["social-media@x"]}`: "invalid rule pack version: social-media@x, expected e.g. social-media@1",
+		`{"use": ["../ignores"]}`:     `unknown rule pack "../ignores", expected one of: bot-protection, rate-limited-cdns, social-media`,
+	} {
+		ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+		assert.Nil(t, os.WriteFile(ignoreListFile, []byte(content), 0644))
+		_, err := loadIgnores(&arguments{IgnoresJson: []string{ignoreListFile}})
+		assert.EqualError(t, err, ignoreListFile+": "+expected)
+	}
+
+	_, err := parseAdHocRules("--ignore", `{"use": ["social-media"]}`)
+	assert.EqualError(t, err, "--ignore: rule packs are only supported in ignores files")
+}
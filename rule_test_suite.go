@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v3"
+)
+
+type testArguments struct {
+	IgnoresJson []string `short:"i" long:"ignores" description:"File or http(s) url containing url errors to ignore. Can be repeated. Defaults to the same ignores files as the check"`
+	Ignore      []string `long:"ignore" description:"Ad hoc ignore rule url-pattern=error-pattern. Can be repeated"`
+	SiteUrl     string   `long:"site-url" description:"Url of the checked website, to resolve site-relative rules and ${SITE_URL}. Defaults to $SITE_URL"`
+	Verbose     bool     `short:"v" long:"verbose" description:"Also show the passed cases"`
+	Help        bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+const testUsage = "test [options] <cases file>..."
+
+const (
+	outcomeIgnored  = "ignored"
+	outcomeReported = "reported"
+)
+
+// ruleTestCase is a broken link with the expected outcome of the ignore rules: "ignored" or "reported". Rule
+// (optional) is the id or the origin, e.g. "ignores.json rule 2", of the rule expected to ignore the link.
+type ruleTestCase struct {
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	Page   string `json:"page,omitempty" yaml:"page,omitempty"`
+	Url    string `json:"url" yaml:"url"`
+	Error  string `json:"error" yaml:"error"`
+	Expect string `json:"expect" yaml:"expect"`
+	Rule   string `json:"rule,omitempty" yaml:"rule,omitempty"`
+}
+
+var ruleTestCaseFields = getJsonFieldNames(reflect.TypeOf(ruleTestCase{}))
+
+// decodeRuleTestCases decodes a cases file: a list of cases, or an object with cases, in json, jsonc or yaml format.
+func decodeRuleTestCases(casesFile string, doc *yaml.Node) (cases []ruleTestCase, err error) {
+	if doc.Kind == 0 {
+		return
+	}
+	if doc.Kind == yaml.DocumentNode {
+		doc = doc.Content[0]
+	}
+	casesNode := doc
+	if doc.Kind == yaml.MappingNode {
+		if err = checkKnownFields(casesFile, doc, []string{"cases"}, ""); err != nil {
+			return
+		}
+		if len(doc.Content) == 0 {
+			return
+		}
+		casesNode = doc.Content[1]
+	}
+	if casesNode.Kind != yaml.SequenceNode {
+		return nil, newIgnoreFileError(casesFile, casesNode, "expected a list of cases, or an object with cases")
+	}
+
+	for i, caseNode := range casesNode.Content {
+		var testCase ruleTestCase
+		if caseNode.Kind != yaml.MappingNode {
+			return nil, newIgnoreFileError(casesFile, caseNode, "case %d: expected an object", i)
+		}
+		if err = checkKnownFields(casesFile, caseNode, ruleTestCaseFields, fmt.Sprintf("case %d: ", i)); err != nil {
+			return
+		}
+		if err = caseNode.Decode(&testCase); err != nil {
+			return nil, newIgnoreFileError(casesFile, caseNode, "case %d: %v", i, yamlErrorMessage(err))
+		}
+		if testCase.Url == "" {
+			return nil, newIgnoreFileError(casesFile, caseNode, "case %d: missing url", i)
+		} else if testCase.Expect != outcomeIgnored && testCase.Expect != outcomeReported {
+			return nil, newIgnoreFileError(casesFile, caseNode, "case %d: invalid expect %q, expected one of: %s, %s", i,
+				testCase.Expect, outcomeIgnored, outcomeReported)
+		} else if testCase.Expect == outcomeReported && testCase.Rule != "" {
+			return nil, newIgnoreFileError(casesFile, caseNode, "case %d: a reported link is not ignored by a rule", i)
+		}
+		cases = append(cases, testCase)
+	}
+	return
+}
+
+func readRuleTestCases(casesFile string) ([]ruleTestCase, error) {
+	content, err := os.ReadFile(casesFile)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseIgnoreDocument(casesFile, content)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRuleTestCases(casesFile, doc)
+}
+
+// describeOutcome describes the outcome of a case, e.g. "ignored by ignores.json rule 2 (id: 4f2a9c1e)".
+func describeOutcome(rule *IgnoreRule) string {
+	if rule == nil {
+		return outcomeReported
+	} else if rule.Id != "" {
+		return fmt.Sprintf("%s by %s (id: %s)", outcomeIgnored, rule.origin(), rule.Id)
+	}
+	return fmt.Sprintf("%s by %s", outcomeIgnored, rule.origin())
+}
+
+// run filters a report of the single broken link of the case, like the check does, and returns the diff of the
+// expected and the actual outcome, or "" if the case passes.
+func (testCase *ruleTestCase) run(rules []IgnoreRule) (string, error) {
+	errorLink := UrlErrorLink{Url: testCase.Url, Error: testCase.Error}
+	report := Report{UrlsToCheck: []UrlToCheck{{Url: testCase.Page, Links: []interface{}{errorLink}}}}
+	filtered, err := report.filter(rules, false)
+	if err != nil {
+		return "", err
+	}
+
+	var rule *IgnoreRule
+	if len(filtered.UrlsToCheck) == 0 {
+		rule = findMatchingRule(testCase.Page, errorLink, rules)
+	}
+	isPass := rule == nil && testCase.Expect == outcomeReported ||
+		rule != nil && testCase.Expect == outcomeIgnored &&
+			(testCase.Rule == "" || testCase.Rule == rule.Id || testCase.Rule == rule.origin())
+	if isPass {
+		return "", nil
+	}
+
+	expected := testCase.Expect
+	if testCase.Rule != "" {
+		expected += " by " + testCase.Rule
+	}
+	return fmt.Sprintf("- %s\n+ %s", expected, describeOutcome(rule)), nil
+}
+
+// String describes the broken link of the case on one line.
+func (testCase *ruleTestCase) String() string {
+	s := fmt.Sprintf("link: %s, error: %s", testCase.Url, testCase.Error)
+	if testCase.Page != "" {
+		s += ", page: " + testCase.Page
+	}
+	return s
+}
+
+// runTest runs the cases files against the ignore rules, so changes of the ignores files can be tested in CI.
+func (c *commandFilter) runTest(ss []string) (bool, error) {
+	args := testArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&testArguments{}, testUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if len(remaining) == 0 {
+		return false, fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(&testArguments{}, testUsage))
+	}
+
+	rules, err := loadIgnoreList(&arguments{IgnoresJson: args.IgnoresJson, Ignore: args.Ignore, URL: args.SiteUrl})
+	if err != nil {
+		return false, err
+	}
+
+	total, failed := 0, 0
+	for _, casesFile := range remaining {
+		cases, err := readRuleTestCases(casesFile)
+		if err != nil {
+			return false, err
+		}
+		for i, testCase := range cases {
+			total++
+			name := fmt.Sprintf("%s case %d", casesFile, i)
+			if testCase.Name != "" {
+				name += fmt.Sprintf(" %q", testCase.Name)
+			}
+			diff, err := testCase.run(rules)
+			if err != nil {
+				return false, err
+			} else if diff != "" {
+				failed++
+				c.print("FAIL ", name, ": ", testCase.String(), "\n", diff)
+			} else if args.Verbose {
+				c.print("ok   ", name)
+			}
+		}
+	}
+
+	c.print(fmt.Sprintf("%d cases, %d failed", total, failed))
+	return failed == 0, nil
+}
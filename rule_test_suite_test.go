@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeRuleTestFiles(t *testing.T, cases string) (ignoreListFile, casesFile string) {
+	dir := t.TempDir()
+	ignoreListFile = filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[
+  {"id": "linkedin", "url": "linkedin\\.com", "status": "999"},
+  {"url": "https://partner.com/", "error": ".*", "pageExclude": "/checkout"}
+]`), 0644))
+	casesFile = filepath.Join(dir, "cases.yaml")
+	// $IGNORES is replaced with the ignores file, to refer to its rules by origin
+	assert.Nil(t, os.WriteFile(casesFile, []byte(strings.ReplaceAll(cases, "$IGNORES", ignoreListFile)), 0644))
+	return
+}
+
+func TestRunTestPass(t *testing.T) {
+	ignoreListFile, casesFile := writeRuleTestFiles(t, `
+cases:
+  - name: linkedin blocks bots
+    url: https://www.linkedin.com/in/x
+    error: "999"
+    expect: ignored
+    rule: linkedin
+  - url: https://partner.com/
+    error: "503"
+    page: https://my-site.com/about
+    expect: ignored
+    rule: $IGNORES rule 1
+  - url: https://partner.com/
+    error: "503"
+    page: https://my-site.com/checkout
+    expect: reported
+`)
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"test", "-v", "-i", ignoreListFile, casesFile})
+	assert.Nil(t, err)
+	assert.True(t, ok, stdout.String())
+	assert.Equal(t, "ok   "+casesFile+` case 0 "linkedin blocks bots"
+ok   `+casesFile+` case 1
+ok   `+casesFile+` case 2
+3 cases, 0 failed
+`, stdout.String())
+}
+
+func TestRunTestFail(t *testing.T) {
+	ignoreListFile, casesFile := writeRuleTestFiles(t, `[
+  {"url": "https://www.linkedin.com/in/x", "error": "999", "expect": "reported"},
+  {"url": "https://www.linkedin.com/in/x", "error": "404", "expect": "ignored", "rule": "linkedin"},
+  {"url": "https://partner.com/", "error": "503", "expect": "ignored", "rule": "linkedin"}
+]`)
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"test", "-i", ignoreListFile, casesFile})
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "FAIL "+casesFile+` case 0: link: https://www.linkedin.com/in/x, error: 999
+- reported
++ ignored by `+ignoreListFile+` rule 0 (id: linkedin)
+FAIL `+casesFile+` case 1: link: https://www.linkedin.com/in/x, error: 404
+- ignored by linkedin
++ reported
+FAIL `+casesFile+` case 2: link: https://partner.com/, error: 503
+- ignored by linkedin
++ ignored by `+ignoreListFile+` rule 1
+3 cases, 3 failed
+`, stdout.String())
+}
+
+func TestRunTestSiteRelativeRules(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	t.Setenv(siteUrlVariable, "")
+	ignoreListFile, casesFile := writeRuleTestFiles(t, `
+cases:
+  - url: https://preview.my-site.com/downloads/tool.zip
+    error: "404"
+    expect: ignored
+    rule: --ignore rule 0 (ad hoc)
+  - url: https://other.com/downloads/tool.zip
+    error: "404"
+    expect: reported
+`)
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	args := []string{"test", "-i", ignoreListFile, "--ignore", "site:/downloads/=404", casesFile}
+	ok, err := cf.runWithError(append(args, "--site-url", "https://preview.my-site.com/"))
+	assert.Nil(t, err)
+	assert.True(t, ok, stdout.String())
+	assert.Equal(t, "2 cases, 0 failed\n", stdout.String())
+
+	// like the check, the website url defaults to $SITE_URL
+	_, err = cf.runWithError(args)
+	assert.ErrorContains(t, err, "site-relative patterns need an absolute website url")
+	t.Setenv(siteUrlVariable, "https://preview.my-site.com/")
+	stdout.Reset()
+	ok, err = cf.runWithError(args)
+	assert.Nil(t, err)
+	assert.True(t, ok, stdout.String())
+}
+
+func TestDecodeRuleTestCasesErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		`{"tests": []}`:                                     `cases.json:1:2: unknown field "tests", expected one of: cases`,
+		`{"cases": {}}`:                                     "cases.json:1:11: expected a list of cases, or an object with cases",
+		`[{"error": "404"}]`:                                "cases.json:1:2: case 0: missing url",
+		`[{"url": "a", "expect": "ignore"}]`:                `cases.json:1:2: case 0: invalid expect "ignore", expected one of: ignored, reported`,
+		`[{"url": "a", "expect": "reported", "rule": "x"}]`: "cases.json:1:2: case 0: a reported link is not ignored by a rule",
+		`[{"url": "a", "expected": "ignored"}]`:             `cases.json:1:15: case 0: unknown field "expected", expected one of: name, page, url, error, expect, rule`,
+	} {
+		doc, err := parseIgnoreDocument("cases.json", []byte(content))
+		assert.Nil(t, err)
+		_, err = decodeRuleTestCases("cases.json", doc)
+		assert.EqualError(t, err, expected, content)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const findingThreshold = "threshold"
+
+// hasThreshold reports whether the rule only ignores a limited number of links or pages per report.
+func (rule *IgnoreRule) hasThreshold() bool {
+	return rule.MaxOccurrences > 0 || rule.MaxPages > 0
+}
+
+// getExceededRules returns the rules, which match more links or pages of the report than their thresholds allow,
+// with a finding for each. The links of these rules are no longer ignored, because the failures became systemic.
+func (rep *Report) getExceededRules(errorsToIgnore []IgnoreRule) (exceeded map[*IgnoreRule]bool, findings []Finding) {
+	links := map[*IgnoreRule]int{}
+	pages := map[*IgnoreRule]map[string]bool{}
+	for _, errorLink := range rep.errorLinks() {
+		rule := findMatchingRule(errorLink.Page, errorLink.Link, errorsToIgnore)
+		if rule == nil || !rule.hasThreshold() {
+			continue
+		}
+		links[rule]++
+		if pages[rule] == nil {
+			pages[rule] = map[string]bool{}
+		}
+		pages[rule][errorLink.Page] = true
+	}
+
+	exceeded = map[*IgnoreRule]bool{}
+	for i := range errorsToIgnore {
+		rule := &errorsToIgnore[i]
+		var limits []string
+		if rule.MaxOccurrences > 0 && links[rule] > rule.MaxOccurrences {
+			limits = append(limits, fmt.Sprintf("maxOccurrences: %d", rule.MaxOccurrences))
+		}
+		if rule.MaxPages > 0 && len(pages[rule]) > rule.MaxPages {
+			limits = append(limits, fmt.Sprintf("maxPages: %d", rule.MaxPages))
+		}
+		if len(limits) > 0 {
+			exceeded[rule] = true
+			findings = append(findings, Finding{Kind: findingThreshold, Message: fmt.Sprintf(
+				"%s (url: %s) matched %d links on %d pages, more than allowed (%s), so these links are not ignored",
+				rule.origin(), rule.Url, links[rule], len(pages[rule]), strings.Join(limits, ", "))})
+		}
+	}
+	return
+}
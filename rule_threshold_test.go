@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonReportThresholds = `[
+  {
+    "url": "https://my-site.com/",
+    "links": [
+      {"url": "https://partner.com/a", "error": "503"},
+      {"url": "https://partner.com/b", "error": "503"},
+      {"url": "https://other.com/", "error": "404"}
+    ]
+  },
+  {
+    "url": "https://my-site.com/about",
+    "links": [
+      {"url": "https://partner.com/a", "error": "503"}
+    ]
+  }
+]`
+
+func loadThresholdsReport(t *testing.T) Report {
+	report, err := (&parseResponse{jsonReportThresholds}).loadReport(&arguments{})
+	assert.Nil(t, err)
+	return report
+}
+
+func TestReportFilterThresholdNotExceeded(t *testing.T) {
+	report := loadThresholdsReport(t)
+	filtered, err := report.filter([]IgnoreRule{
+		{Url: "^https://partner\\.com/", Error: "503", MaxOccurrences: 3, MaxPages: 2},
+	}, false)
+	assert.Nil(t, err)
+	assert.Nil(t, filtered.Findings)
+	assert.Equal(t, []UrlToCheck{
+		{Url: "https://my-site.com/", Links: []interface{}{UrlErrorLink{Url: "https://other.com/", Error: "404"}}},
+	}, filtered.UrlsToCheck)
+}
+
+func TestReportFilterMaxOccurrencesExceeded(t *testing.T) {
+	report := loadThresholdsReport(t)
+	filtered, err := report.filter([]IgnoreRule{
+		{Url: "https://other.com/", Error: "404", source: "ignores.json", index: 0},
+		{Url: "^https://partner\\.com/", Error: "503", MaxOccurrences: 2, source: "ignores.json", index: 1},
+	}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []Finding{{Kind: "threshold", Message: "ignores.json rule 1 (url: ^https://partner\\.com/) matched 3 links on 2 pages, more than allowed (maxOccurrences: 2), so these links are not ignored"}},
+		filtered.Findings)
+	assert.Equal(t, 2, len(filtered.UrlsToCheck))
+	assert.Equal(t, 2, len(filtered.UrlsToCheck[0].Links))
+	assert.Equal(t, 1, len(filtered.UrlsToCheck[1].Links))
+}
+
+func TestReportFilterMaxPagesExceeded(t *testing.T) {
+	report := loadThresholdsReport(t)
+	filtered, err := report.filter([]IgnoreRule{
+		{Url: "^https://partner\\.com/a$", Error: "503", MaxPages: 1, source: "ignores.json"},
+		{Url: "^https://partner\\.com/", Error: "503", MaxPages: 1, source: "ignores.json", index: 1},
+	}, false)
+	assert.Nil(t, err)
+	// the first rule matches partner.com/a on 2 pages, the second rule only partner.com/b on 1 page
+	assert.Equal(t, []Finding{{Kind: "threshold", Message: "ignores.json rule 0 (url: ^https://partner\\.com/a$) matched 2 links on 2 pages, more than allowed (maxPages: 1), so these links are not ignored"}},
+		filtered.Findings)
+	assert.Equal(t, []UrlToCheck{
+		{Url: "https://my-site.com/", Links: []interface{}{
+			UrlErrorLink{Url: "https://partner.com/a", Error: "503"},
+			UrlErrorLink{Url: "https://other.com/", Error: "404"},
+		}},
+		{Url: "https://my-site.com/about", Links: []interface{}{UrlErrorLink{Url: "https://partner.com/a", Error: "503"}}},
+	}, filtered.UrlsToCheck)
+}
+
+func TestDecodeIgnoreFileThresholds(t *testing.T) {
+	doc, err := parseIgnoreDocument("ignores.yaml", []byte(`
+- url: ^https://partner\.com/
+  error: "5.."
+  maxOccurrences: 3
+  maxPages: 2
+`))
+	assert.Nil(t, err)
+	file, err := decodeIgnoreFile("ignores.yaml", doc)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, file.Rules[0].MaxOccurrences)
+	assert.Equal(t, 2, file.Rules[0].MaxPages)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+const schemaUsage = "schema [ignores|report]"
+
+// jsonSchemaOneOf lists the types of interface fields, named by their schema struct tag.
+var jsonSchemaOneOf = map[string][]reflect.Type{
+	"link": {reflect.TypeOf(UrlSuccessLink{}), reflect.TypeOf(urlErrorLinkOutput{})},
+}
+
+// getJsonFields returns the json names of the exported fields of a struct, including those of embedded structs,
+// and whether each field is optional.
+func getJsonFields(t reflect.Type) (fields []reflect.StructField, names []string, optional []bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			embeddedFields, embeddedNames, embeddedOptional := getJsonFields(field.Type)
+			fields = append(fields, embeddedFields...)
+			names = append(names, embeddedNames...)
+			optional = append(optional, embeddedOptional...)
+			continue
+		}
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, field)
+		names = append(names, name)
+		optional = append(optional, strings.Contains(options, "omitempty"))
+	}
+	return
+}
+
+// getJsonFieldNames returns the json names of the fields of a struct.
+func getJsonFieldNames(t reflect.Type) []string {
+	_, names, _ := getJsonFields(t)
+	return names
+}
+
+// newJsonSchema generates the json schema of a type from its json struct tags. Fields without omitempty are required.
+func newJsonSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(errorCategory("")) {
+		return map[string]any{"type": "string", "enum": errorCategories}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": newJsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": newJsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		fields, names, optional := getJsonFields(t)
+		for i, field := range fields {
+			if types, ok := jsonSchemaOneOf[field.Tag.Get("schema")]; ok {
+				var oneOf []any
+				for _, oneOfType := range types {
+					oneOf = append(oneOf, newJsonSchema(oneOfType))
+				}
+				properties[names[i]] = map[string]any{"type": "array", "items": map[string]any{"oneOf": oneOf}}
+			} else {
+				properties[names[i]] = newJsonSchema(field.Type)
+			}
+			if !optional[i] {
+				required = append(required, names[i])
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	// any value
+	return map[string]any{}
+}
+
+// getIgnoresFileSchema returns the json schema of ignores files: a list of rules, or an object holding the rules.
+func getIgnoresFileSchema() map[string]any {
+	rules := newJsonSchema(reflect.TypeOf([]IgnoreRule{}))
+	return map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "muffet-filter ignores file",
+		"oneOf":   []any{rules, newJsonSchema(reflect.TypeOf(ignoreFile{}))},
+	}
+}
+
+// getReportSchema returns the json schema of the filtered report written by the check.
+func getReportSchema() map[string]any {
+	schema := newJsonSchema(reflect.TypeOf(Report{}))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "muffet-filter report"
+	return schema
+}
+
+// runSchema prints the json schema of ignores files, or of the filtered report.
+func (c *commandFilter) runSchema(ss []string) (bool, error) {
+	var args struct {
+		Help bool `short:"h" long:"help" description:"Show this help"`
+	}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&args, schemaUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var schema map[string]any
+	switch strings.Join(remaining, " ") {
+	case "", "ignores":
+		schema = getIgnoresFileSchema()
+	case "report":
+		schema = getReportSchema()
+	default:
+		return false, fmt.Errorf("unknown schema: %s, expected ignores or report", strings.Join(remaining, " "))
+	}
+	prettyJson, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	c.print(string(prettyJson))
+	return true, nil
+}
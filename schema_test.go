@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJsonFieldNames(t *testing.T) {
+	assert.Equal(t, []string{"id", "url", "error", "page", "pageExclude", "status", "category", "when", "reason", "added", "expires", "maxOccurrences", "maxPages"},
+		getJsonFieldNames(reflect.TypeOf(IgnoreRule{})))
+	// fields of embedded structs are included
+	assert.Equal(t, []string{"url", "error", "category", "statusCode"}, getJsonFieldNames(reflect.TypeOf(urlErrorLinkOutput{})))
+}
+
+func TestNewJsonSchema(t *testing.T) {
+	schema := newJsonSchema(reflect.TypeOf(Expectation{}))
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, false, schema["additionalProperties"])
+	assert.Equal(t, []string{"url"}, schema["required"])
+	assert.Equal(t, map[string]any{"type": "string"}, schema["properties"].(map[string]any)["status"])
+
+	schema = newJsonSchema(reflect.TypeOf(urlErrorLinkOutput{}))
+	assert.Equal(t, map[string]any{"type": "string", "enum": errorCategories}, schema["properties"].(map[string]any)["category"])
+	assert.Equal(t, map[string]any{"type": "integer"}, schema["properties"].(map[string]any)["statusCode"])
+}
+
+// the published schema files must match the generated schemas
+func TestSchemaFilesUpToDate(t *testing.T) {
+	for file, schema := range map[string]map[string]any{
+		"schema/ignores.schema.json": getIgnoresFileSchema(),
+		"schema/report.schema.json":  getReportSchema(),
+	} {
+		expected, err := json.MarshalIndent(schema, "", "  ")
+		assert.Nil(t, err)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected)+"\n", string(content), "outdated %s, regenerate it with the schema command", file)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"schema"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), `"title": "muffet-filter ignores file"`)
+
+	stdout.Reset()
+	ok = cf.Run([]string{"schema", "report"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), `"title": "muffet-filter report"`)
+
+	ok = cf.Run([]string{"schema", "config"})
+	assert.False(t, ok)
+	assert.Contains(t, stderr.String(), "unknown schema: config, expected ignores or report")
+}
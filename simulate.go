@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+type simulateArguments struct {
+	Old           []string `long:"old" description:"Ignores file before the change. Can be repeated. Without it, no links are ignored before the change"`
+	New           []string `long:"new" description:"Ignores file after the change. Can be repeated" required:"true"`
+	MuffetJson    []string `short:"j" long:"input-json" description:"Path to a stored muffet report in json format. Can be repeated" required:"true"`
+	SiteUrl       string   `long:"site-url" description:"Url of the checked website, to resolve site-relative rules and ${SITE_URL}. Defaults to $SITE_URL"`
+	Normalize     bool     `long:"normalize" description:"Normalize link and page urls before matching: lowercase the host, drop default ports, convert IDN hosts to punycode, normalize percent-encoding, and merge duplicate pages"`
+	StripQuery    []string `long:"strip-query" description:"Remove the query parameter from urls, e.g. lang, or * to remove the whole query. Can be repeated. Implies --normalize"`
+	SortQuery     bool     `long:"sort-query" description:"Sort the query parameters of urls by name. Implies --normalize"`
+	StripFragment bool     `long:"strip-fragment" description:"Remove the #fragment from urls. Implies --normalize"`
+	Help          bool     `short:"h" long:"help" description:"Show this help"`
+}
+
+// checkArguments returns the arguments of a check with the ignores files, like the one producing the reports.
+func (args *simulateArguments) checkArguments(ignoresFiles []string) *arguments {
+	return &arguments{IgnoresJson: ignoresFiles, URL: args.SiteUrl, Normalize: args.Normalize, StripQuery: args.StripQuery,
+		SortQuery: args.SortQuery, StripFragment: args.StripFragment}
+}
+
+const simulateUsage = "simulate [options] --new <ignores file> -j <report>..."
+
+// getIgnoringRules returns the rule ignoring each error link of the report, in the order of errorLinks, or nil if the
+// link is reported. Like filter, rules above their thresholds do not ignore their links.
+func (rep *Report) getIgnoringRules(rules []IgnoreRule) (ignoring []*IgnoreRule) {
+	exceeded, _ := rep.getExceededRules(rules)
+	for _, errorLink := range rep.errorLinks() {
+		rule := findMatchingRule(errorLink.Page, errorLink.Link, rules)
+		if exceeded[rule] {
+			rule = nil
+		}
+		ignoring = append(ignoring, rule)
+	}
+	return
+}
+
+// ruleDelta counts the links a rule ignores before and after the change. Rules are identified by their content, as
+// the old and new ignores files usually have different names.
+type ruleDelta struct {
+	rule     string
+	old, new int
+}
+
+// simulation is the effect of changing the ignore rules on stored reports.
+type simulation struct {
+	oldRules, newRules []IgnoreRule
+	lines              []string
+	deltas             map[string]*ruleDelta
+}
+
+func (s *simulation) delta(rule *IgnoreRule) *ruleDelta {
+	key := rule.String()
+	if s.deltas[key] == nil {
+		s.deltas[key] = &ruleDelta{rule: key}
+	}
+	return s.deltas[key]
+}
+
+// markdownCode formats the text as inline code in a markdown table, where | separates the cells.
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(text, "|", `\|`) + "`"
+}
+
+func describeRule(rule *IgnoreRule) string {
+	if rule.Id != "" {
+		return markdownCode(rule.Id)
+	}
+	return markdownCode(rule.origin())
+}
+
+// simulateReport adds the links of the report, which become suppressed or unsuppressed by the change.
+func (s *simulation) simulateReport(name string, report Report) {
+	errorLinks := report.errorLinks()
+	oldIgnoring := report.getIgnoringRules(s.oldRules)
+	newIgnoring := report.getIgnoringRules(s.newRules)
+
+	var suppressed, unsuppressed []string
+	for i, errorLink := range errorLinks {
+		oldRule, newRule := oldIgnoring[i], newIgnoring[i]
+		if oldRule != nil {
+			s.delta(oldRule).old++
+		}
+		if newRule != nil {
+			s.delta(newRule).new++
+		}
+		row := fmt.Sprintf("| %s | %s | %s |", markdownCode(errorLink.Link.Url), markdownCode(errorLink.Link.Error),
+			markdownCode(errorLink.Page))
+		if oldRule == nil && newRule != nil {
+			suppressed = append(suppressed, fmt.Sprintf("%s %s |", row, describeRule(newRule)))
+		} else if oldRule != nil && newRule == nil {
+			unsuppressed = append(unsuppressed, fmt.Sprintf("%s %s |", row, describeRule(oldRule)))
+		}
+	}
+
+	s.lines = append(s.lines, "", "#### "+name)
+	if len(suppressed) == 0 && len(unsuppressed) == 0 {
+		s.lines = append(s.lines, "", fmt.Sprintf("No changes, %d broken links.", len(errorLinks)))
+		return
+	}
+	for _, table := range []struct {
+		title, rule string
+		rows        []string
+	}{
+		{"Newly suppressed", "Ignored by", suppressed},
+		{"No longer suppressed", "Was ignored by", unsuppressed},
+	} {
+		if len(table.rows) == 0 {
+			continue
+		}
+		s.lines = append(s.lines, "", fmt.Sprintf("**%s (%d)**", table.title, len(table.rows)), "",
+			"| Link | Error | Page | "+table.rule+" |", "| --- | --- | --- | --- |")
+		s.lines = append(s.lines, table.rows...)
+	}
+}
+
+// deltaSummary returns a table of the rules, whose number of ignored links changed.
+func (s *simulation) deltaSummary() []string {
+	var deltas []*ruleDelta
+	for _, delta := range s.deltas {
+		if delta.old != delta.new {
+			deltas = append(deltas, delta)
+		}
+	}
+	if len(deltas) == 0 {
+		return []string{"", "#### Rules", "", "No rule ignores a different number of links."}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].rule < deltas[j].rule })
+
+	lines := []string{"", "#### Rules", "", "| Rule | Before | After | Delta |", "| --- | ---: | ---: | ---: |"}
+	for _, delta := range deltas {
+		lines = append(lines, fmt.Sprintf("| %s | %d | %d | %+d |", markdownCode(delta.rule), delta.old, delta.new, delta.new-delta.old))
+	}
+	return lines
+}
+
+// runSimulate shows the effect of changing the ignores files on stored muffet reports, as markdown for a PR comment.
+func (c *commandFilter) runSimulate(ss []string) (bool, error) {
+	args := simulateArguments{}
+	remaining, err := flags.NewParser(&args, flags.PassDoubleDash).ParseArgs(ss)
+	if args.Help {
+		c.print(commandHelp(&simulateArguments{}, simulateUsage))
+		return true, nil
+	} else if err != nil {
+		return false, err
+	} else if len(remaining) != 0 {
+		return false, fmt.Errorf("invalid number of arguments\n\n%s", commandHelp(&simulateArguments{}, simulateUsage))
+	}
+
+	s := simulation{deltas: map[string]*ruleDelta{}}
+	if len(args.Old) > 0 {
+		if s.oldRules, err = loadIgnoreList(args.checkArguments(args.Old)); err != nil {
+			return false, err
+		}
+	}
+	if s.newRules, err = loadIgnoreList(args.checkArguments(args.New)); err != nil {
+		return false, err
+	}
+	options := args.checkArguments(nil).getNormalizeOptions()
+
+	s.lines = []string{"### Simulation of the ignore rule changes"}
+	for _, reportFile := range args.MuffetJson {
+		content, err := os.ReadFile(reportFile)
+		if err != nil {
+			return false, err
+		}
+		report, err := (&parseResponse{string(content)}).loadReport(&arguments{})
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", reportFile, err)
+		}
+		if options != nil {
+			report = report.normalize(options)
+		}
+		s.simulateReport(reportFile, report)
+	}
+	s.lines = append(s.lines, s.deltaSummary()...)
+	c.print(strings.Join(s.lines, "\n"))
+	return true, nil
+}
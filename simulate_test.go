@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSimulate(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.json")
+	assert.Nil(t, os.WriteFile(oldFile, []byte(`[
+  {"url": "https://partner.com/", "error": "503"},
+  {"id": "twitter", "url": "https://twitter.com/"}
+]`), 0644))
+	newFile := filepath.Join(dir, "new.json")
+	assert.Nil(t, os.WriteFile(newFile, []byte(`[
+  {"url": "https://partner.com/", "error": "50[0-9]|timeout"},
+  {"url": "https://my-site.com/pricing"}
+]`), 0644))
+	reportFile := filepath.Join(dir, "report.json")
+	assert.Nil(t, os.WriteFile(reportFile, []byte(`[{"url": "https://my-site.com/", "links": [
+  {"url": "https://partner.com/", "error": "503"},
+  {"url": "https://partner.com/", "error": "504"},
+  {"url": "https://twitter.com/", "error": "429"},
+  {"url": "https://my-site.com/pricing", "error": "404"}
+]}]`), 0644))
+	unchangedFile := filepath.Join(dir, "unchanged.json")
+	assert.Nil(t, os.WriteFile(unchangedFile, []byte(`[{"url": "https://my-site.com/", "links": [
+  {"url": "https://cdn.com/", "error": "404"}
+]}]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"simulate", "--old", oldFile, "--new", newFile, "-j", reportFile, "-j", unchangedFile})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "### Simulation of the ignore rule changes\n\n#### "+reportFile+"\n\n"+
+		"**Newly suppressed (2)**\n\n"+
+		"| Link | Error | Page | Ignored by |\n| --- | --- | --- | --- |\n"+
+		"| `https://partner.com/` | `504` | `https://my-site.com/` | `"+newFile+" rule 0` |\n"+
+		"| `https://my-site.com/pricing` | `404` | `https://my-site.com/` | `"+newFile+" rule 1` |\n\n"+
+		"**No longer suppressed (1)**\n\n"+
+		"| Link | Error | Page | Was ignored by |\n| --- | --- | --- | --- |\n"+
+		"| `https://twitter.com/` | `429` | `https://my-site.com/` | `twitter` |\n\n"+
+		"#### "+unchangedFile+"\n\nNo changes, 1 broken links.\n\n"+
+		"#### Rules\n\n| Rule | Before | After | Delta |\n| --- | ---: | ---: | ---: |\n"+
+		"| `id: twitter, url: https://twitter.com/, error: ` | 1 | 0 | -1 |\n"+
+		"| `url: https://my-site.com/pricing, error: ` | 0 | 1 | +1 |\n"+
+		"| `url: https://partner.com/, error: 503` | 1 | 0 | -1 |\n"+
+		"| `url: https://partner.com/, error: 50[0-9]\\|timeout` | 0 | 2 | +2 |\n", stdout.String())
+}
+
+func TestRunSimulateWithoutOld(t *testing.T) {
+	dir := t.TempDir()
+	newFile := filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(newFile, []byte(`[{"url": "https://partner.com/"}]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"simulate", "--new", newFile, "-j", "testdata/reportErrorsOnly.json"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	// without old ignores files, every ignored link is newly suppressed
+	assert.NotContains(t, stdout.String(), "No longer suppressed")
+
+	_, err = cf.runWithError([]string{"simulate", "--new", newFile})
+	assert.EqualError(t, err, "the required flag `-j, --input-json' was not specified")
+}
+
+func TestRunSimulateLikeCheck(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	t.Setenv(siteUrlVariable, "")
+	dir := t.TempDir()
+	newFile := filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(newFile, []byte(`[{"url": "site:/downloads/tool\\.zip$", "error": "404"}]`), 0644))
+	reportFile := filepath.Join(dir, "report.json")
+	assert.Nil(t, os.WriteFile(reportFile, []byte(`[{"url": "https://my-site.com/", "links": [
+  {"url": "HTTPS://My-Site.com:443/downloads/tool.zip?utm_source=mail", "error": "404"}
+]}]`), 0644))
+
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	// the site-relative rule is resolved against the website, and the report is normalized like in the check
+	ok, err := cf.runWithError([]string{"simulate", "--new", newFile, "-j", reportFile,
+		"--site-url", "https://my-site.com/", "--strip-query", "utm_source"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "**Newly suppressed (1)**")
+	assert.Contains(t, stdout.String(), "| `https://my-site.com/downloads/tool.zip` | `404` | `https://my-site.com/` |")
+
+	_, err = cf.runWithError([]string{"simulate", "--new", newFile, "-j", reportFile})
+	assert.ErrorContains(t, err, "site-relative patterns need an absolute website url")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// siteRelativePrefix marks url patterns relative to the checked website, e.g. "site:/docs/". Without it, patterns
+// starting with "/" are regular expressions like any other.
+const siteRelativePrefix = "site:"
+
+const siteBasePrefix = "~/"
+
+// isSiteRelativePattern reports whether the url pattern is relative to the checked website: "site:/docs/" is relative to
+// its host, "site:~/docs/" to its url, e.g. a site at https://example.github.io/project/.
+func isSiteRelativePattern(pattern string) bool {
+	return strings.HasPrefix(pattern, siteRelativePrefix)
+}
+
+// resolveSitePattern anchors a site-relative pattern at the url of the checked website. The rest of the pattern is
+// still a regular expression.
+func resolveSitePattern(pattern string, siteUrl *url.URL) (string, error) {
+	path := strings.TrimPrefix(pattern, siteRelativePrefix)
+	switch {
+	case strings.HasPrefix(path, siteBasePrefix):
+		base := siteUrl.Scheme + "://" + siteUrl.Host + siteUrl.EscapedPath()
+		if !strings.HasSuffix(base, "/") {
+			base += "/"
+		}
+		return "^" + regexp.QuoteMeta(base) + strings.TrimPrefix(path, siteBasePrefix), nil
+	case strings.HasPrefix(path, "/"):
+		return "^" + regexp.QuoteMeta(siteUrl.Scheme+"://"+siteUrl.Host) + path, nil
+	}
+	return "", fmt.Errorf("invalid site-relative pattern: %s, expected %s/path or %s%spath", pattern, siteRelativePrefix, siteRelativePrefix, siteBasePrefix)
+}
+
+// hasSiteUrl reports whether the url of the checked website is known, from the command line or $SITE_URL.
+func hasSiteUrl(args *arguments) bool {
+	siteUrl, _ := lookupPatternVariable(siteUrlVariable, args.URL)
+	return siteUrl != ""
+}
+
+// getSiteUrl returns the url of the checked website, or $SITE_URL when reading a report via --input-json.
+func getSiteUrl(args *arguments) (*url.URL, error) {
+	siteUrl, ok := lookupPatternVariable(siteUrlVariable, args.URL)
+	if !ok {
+		return nil, fmt.Errorf("site-relative patterns need the url of the website to check, or $%s", siteUrlVariable)
+	}
+	u, err := url.Parse(siteUrl)
+	if err != nil {
+		return nil, err
+	} else if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("site-relative patterns need an absolute website url, got: %s", siteUrl)
+	}
+	return u, nil
+}
+
+// resolveSiteRelativeRules resolves the site-relative url patterns of the rules, so the same ignores file works against
+// localhost, preview deployments and production. Page patterns are not resolved, as "/archive/" commonly matches the
+// path of any page.
+func resolveSiteRelativeRules(rules []IgnoreRule, args *arguments) error {
+	var siteUrl *url.URL
+	for i := range rules {
+		rule := &rules[i]
+		if !isSiteRelativePattern(rule.Url) {
+			continue
+		}
+		if siteUrl == nil {
+			var err error
+			if siteUrl, err = getSiteUrl(args); err != nil {
+				return fmt.Errorf("%s: %w", rule.origin(), err)
+			}
+		}
+		var err error
+		if rule.Url, err = resolveSitePattern(rule.Url, siteUrl); err != nil {
+			return fmt.Errorf("%s: %w", rule.origin(), err)
+		}
+	}
+	return nil
+}
+
+// resolveSitePatterns resolves ${SITE_URL} and the site-relative url patterns of the loaded rules and expectations.
+func resolveSitePatterns(ignores *ignoreFile, args *arguments) error {
+	if err := resolveSiteUrlReferences(ignores, args); err != nil {
+		return err
+	}
+	return resolveSiteRelativeRules(ignores.Rules, args)
+}
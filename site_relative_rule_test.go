@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSitePattern(t *testing.T) {
+	for _, tc := range []struct {
+		siteUrl, pattern, expected string
+	}{
+		{"https://example.github.io/project", "site:/docs/.*\\.pdf", `^https://example\.github\.io/docs/.*\.pdf`},
+		{"https://example.github.io/project", "site:~/docs/", `^https://example\.github\.io/project/docs/`},
+		{"http://localhost:4000/", "site:~/docs/", `^http://localhost:4000/docs/`},
+	} {
+		siteUrl, _ := url.Parse(tc.siteUrl)
+		pattern, err := resolveSitePattern(tc.pattern, siteUrl)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, pattern)
+	}
+
+	siteUrl, _ := url.Parse("https://my-site.com/")
+	_, err := resolveSitePattern("site:docs/", siteUrl)
+	assert.EqualError(t, err, "invalid site-relative pattern: site:docs/, expected site:/path or site:~/path")
+}
+
+func TestResolveSiteRelativeRules(t *testing.T) {
+	t.Setenv(siteUrlVariable, "")
+	rules := []IgnoreRule{
+		{Url: "site:/downloads/", Error: "404", Page: "/archive/"},
+		{Url: "site:~/beta/", Error: "404"},
+		{Url: "https://partner.com/", Error: "503"},
+		// without the marker, a leading "/" is part of the regular expression, which matches the path on any host
+		{Url: "/docs/", Error: "404"},
+	}
+	assert.Nil(t, resolveSiteRelativeRules(rules, &arguments{URL: "https://pr-42.preview.my-site.com/docs/"}))
+	assert.Equal(t, `^https://pr-42\.preview\.my-site\.com/downloads/`, rules[0].Url)
+	assert.Equal(t, "/archive/", rules[0].Page)
+	assert.Equal(t, `^https://pr-42\.preview\.my-site\.com/docs/beta/`, rules[1].Url)
+	assert.Equal(t, "https://partner.com/", rules[2].Url)
+	assert.Equal(t, "/docs/", rules[3].Url)
+
+	assert.True(t, rules[0].isMatch("https://pr-42.preview.my-site.com/archive/2020/", UrlErrorLink{Url: "https://pr-42.preview.my-site.com/downloads/tool.zip", Error: "404"}))
+	assert.False(t, rules[0].isMatch("https://pr-42.preview.my-site.com/archive/2020/", UrlErrorLink{Url: "https://my-site.com/downloads/tool.zip", Error: "404"}))
+}
+
+func TestResolveSiteRelativeRulesWithoutSiteUrl(t *testing.T) {
+	t.Setenv(siteUrlVariable, "")
+	// patterns starting with "/" do not need the url of the website
+	rules := []IgnoreRule{{Url: "/downloads/", source: "ignores.json", index: 3}}
+	assert.Nil(t, resolveSiteRelativeRules(rules, &arguments{MuffetJson: "report.json"}))
+	assert.Equal(t, "/downloads/", rules[0].Url)
+
+	rules = []IgnoreRule{{Url: "site:/downloads/", source: "ignores.json", index: 3}}
+	assert.EqualError(t, resolveSiteRelativeRules(rules, &arguments{MuffetJson: "report.json"}),
+		"ignores.json rule 3: site-relative patterns need an absolute website url, got: ")
+
+	t.Setenv(siteUrlVariable, "https://my-site.com")
+	assert.Nil(t, resolveSiteRelativeRules(rules, &arguments{MuffetJson: "report.json"}))
+	assert.Equal(t, `^https://my-site\.com/downloads/`, rules[0].Url)
+}
+
+func TestCommandFilter_SiteRelativeRule(t *testing.T) {
+	t.Setenv(adHocIgnoresEnvVar, "")
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	mockExec := &mockMuffetExecutor{result: `[
+  {"url": "http://localhost:4000/", "links": [
+    {"url": "http://localhost:4000/downloads/tool.zip", "error": "404"}
+  ]}
+]`}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{executor: mockExec})
+
+	assert.True(t, cf.Run([]string{"--ignore", "site:/downloads/=404", "http://localhost:4000/"}))
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v3"
+)
+
+// The rules added and removed by subcommands are spliced into the content of an ignores file, so the other bytes stay
+// as they are. Layouts where the bytes of a rule are not on lines of their own, e.g. a list of rules on a single line,
+// are not spliced, instead the whole document is written.
+
+// byteEdit replaces the bytes from start to end of the content of an ignores file.
+type byteEdit struct {
+	start, end int
+	text       string
+}
+
+// applyByteEdits applies edits, which do not overlap, to the content.
+func applyByteEdits(content []byte, edits []byteEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	result := bytes.Clone(content)
+	for _, edit := range edits {
+		result = append(result[:edit.start:edit.start], append([]byte(edit.text), result[edit.end:]...)...)
+	}
+	return result
+}
+
+// getLineStart returns the offset of the line of the offset, and whether only spaces precede the offset on the line.
+func getLineStart(content []byte, offset int) (int, bool) {
+	start := bytes.LastIndexByte(content[:offset], '\n') + 1
+	return start, strings.TrimLeft(string(content[start:offset]), " \t") == ""
+}
+
+// getLineEnd returns the offset after the newline ending the line of the offset, and whether only spaces and comments
+// follow the offset on the line.
+func getLineEnd(content []byte, offset int) (int, bool) {
+	for offset < len(content) {
+		switch {
+		case content[offset] == ' ' || content[offset] == '\t' || content[offset] == '\r':
+			offset++
+		case content[offset] == '\n':
+			return offset + 1, true
+		case bytes.HasPrefix(content[offset:], []byte("//")):
+			end := bytes.IndexByte(content[offset:], '\n')
+			if end < 0 {
+				return len(content), true
+			}
+			offset += end
+		case bytes.HasPrefix(content[offset:], []byte("/*")):
+			end := bytes.Index(content[offset:], []byte("*/"))
+			if end < 0 || bytes.IndexByte(content[offset:offset+end], '\n') >= 0 {
+				return offset, false
+			}
+			offset += end + len("*/")
+		default:
+			return offset, false
+		}
+	}
+	return offset, true
+}
+
+// getJsoncCommentLinesStart returns the start of the "//" comment lines directly above the line start, after the
+// offset.
+func getJsoncCommentLinesStart(content []byte, start, after int) int {
+	for start > after {
+		previous, _ := getLineStart(content, start-1)
+		if previous < after || !strings.HasPrefix(strings.TrimSpace(string(content[previous:start])), "//") {
+			break
+		}
+		start = previous
+	}
+	return start
+}
+
+// getJsoncRulesArray returns the list of rules of a JSON with comments document, or nil if there is none.
+func getJsoncRulesArray(root *hujson.Value) *hujson.Value {
+	switch value := root.Value.(type) {
+	case *hujson.Array:
+		return root
+	case *hujson.Object:
+		for i := range value.Members {
+			member := &value.Members[i]
+			if name, ok := member.Name.Value.(hujson.Literal); ok && name.String() == "rules" {
+				if _, ok = member.Value.Value.(*hujson.Array); ok {
+					return &member.Value
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// formatJsoncRules returns the rules as JSON objects separated by commas, with the lines after the first indented.
+func formatJsoncRules(rules []IgnoreRule, indent string) (string, error) {
+	var texts []string
+	for _, rule := range rules {
+		node := &yaml.Node{}
+		if err := node.Encode(rule); err != nil {
+			return "", err
+		}
+		b := &bytes.Buffer{}
+		writeJsoncNode(b, node, 0)
+		texts = append(texts, strings.ReplaceAll(b.String(), "\n", "\n"+indent))
+	}
+	return strings.Join(texts, ",\n"+indent), nil
+}
+
+// spliceJsoncAppend adds the rules after the last rule of a JSON with comments document, with its indent.
+func spliceJsoncAppend(content []byte, rules []IgnoreRule) ([]byte, bool, error) {
+	root, err := hujson.Parse(content)
+	if err != nil {
+		return nil, false, nil
+	}
+	rulesArray := getJsoncRulesArray(&root)
+	if rulesArray == nil {
+		return nil, false, nil
+	}
+	elements := rulesArray.Value.(*hujson.Array).Elements
+
+	if len(elements) == 0 {
+		// an empty list, without comments
+		opening, closing := rulesArray.StartOffset, rulesArray.EndOffset-1
+		if strings.TrimSpace(string(content[opening+1:closing])) != "" {
+			return nil, false, nil
+		}
+		lineStart, _ := getLineStart(content, opening)
+		lineIndent := string(content[lineStart:opening])
+		lineIndent = lineIndent[:len(lineIndent)-len(strings.TrimLeft(lineIndent, " \t"))]
+		text, err := formatJsoncRules(rules, lineIndent+"  ")
+		if err != nil {
+			return nil, false, err
+		}
+		return applyByteEdits(content, []byteEdit{{opening + 1, closing, "\n" + lineIndent + "  " + text + "\n" + lineIndent}}), true, nil
+	}
+
+	last := &elements[len(elements)-1]
+	lineStart, ok := getLineStart(content, last.StartOffset)
+	if !ok {
+		return nil, false, nil
+	}
+	indent := string(content[lineStart:last.StartOffset])
+	end := last.EndOffset
+	hasTrailingComma := last.AfterExtra != nil
+	if hasTrailingComma {
+		end += len(last.AfterExtra) + len(",")
+	}
+	lineEnd, ok := getLineEnd(content, end)
+	if !ok || !bytes.HasSuffix(content[:lineEnd], []byte("\n")) {
+		return nil, false, nil
+	}
+	text, err := formatJsoncRules(rules, indent)
+	if err != nil {
+		return nil, false, err
+	}
+	edits := []byteEdit{{lineEnd, lineEnd, indent + text + "\n"}}
+	if hasTrailingComma {
+		edits[0].text = indent + text + ",\n"
+	} else {
+		edits = append(edits, byteEdit{last.EndOffset, last.EndOffset, ","})
+	}
+	return applyByteEdits(content, edits), true, nil
+}
+
+// spliceJsoncRemove removes the lines of the rule at the index of a JSON with comments document, with the comments
+// on the same lines and the comment lines directly above it.
+func spliceJsoncRemove(content []byte, index int) ([]byte, bool) {
+	root, err := hujson.Parse(content)
+	if err != nil {
+		return nil, false
+	}
+	rulesArray := getJsoncRulesArray(&root)
+	if rulesArray == nil || index >= len(rulesArray.Value.(*hujson.Array).Elements) {
+		return nil, false
+	}
+	elements := rulesArray.Value.(*hujson.Array).Elements
+
+	element := &elements[index]
+	start, ok := getLineStart(content, element.StartOffset)
+	if !ok {
+		return nil, false
+	}
+	start = getJsoncCommentLinesStart(content, start, element.StartOffset-len(element.BeforeExtra))
+	end := element.EndOffset + len(element.AfterExtra)
+	hasComma := index+1 < len(elements) || element.AfterExtra != nil
+	if hasComma {
+		end += len(",")
+	}
+	if end, ok = getLineEnd(content, end); !ok {
+		return nil, false
+	}
+	edits := []byteEdit{{start, end, ""}}
+	if !hasComma && index > 0 {
+		// the rule before is the last one now
+		previous := &elements[index-1]
+		comma := previous.EndOffset + len(previous.AfterExtra)
+		edits = append(edits, byteEdit{comma, comma + len(","), ""})
+	}
+	return applyByteEdits(content, edits), true
+}
+
+// isYamlBlankOrComment reports whether the yaml line is empty, or only a comment.
+func isYamlBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// getYamlRuleLines returns the lines of the rules of a yaml document: the first line of each rule, the line after the
+// last rule, and the indent of their "-". Comments before a rule belong to it.
+func getYamlRuleLines(content []byte, lines []string) (starts []int, end int, indent string, ok bool) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil || doc.Kind != yaml.DocumentNode {
+		return nil, 0, "", false
+	}
+	rulesNode, err := getRulesNode(doc)
+	if err != nil || rulesNode.Style&yaml.FlowStyle != 0 || len(rulesNode.Content) == 0 {
+		return nil, 0, "", false
+	}
+	for i, ruleNode := range rulesNode.Content {
+		line := lines[ruleNode.Line-1]
+		prefix := strings.TrimRight(line[:min(ruleNode.Column-1, len(line))], " ")
+		ruleIndent, isItem := strings.CutSuffix(prefix, "-")
+		if !isItem || strings.TrimLeft(ruleIndent, " ") != "" || i > 0 && ruleIndent != indent {
+			return nil, 0, "", false
+		}
+		indent = ruleIndent
+		starts = append(starts, ruleNode.Line-1)
+	}
+
+	// the last rule ends before the next line which is not indented below its "-"
+	end = starts[len(starts)-1] + 1
+	for end < len(lines) && (isYamlBlankOrComment(lines[end]) || strings.HasPrefix(lines[end], indent+" ")) {
+		end++
+	}
+	return starts, end, indent, true
+}
+
+// trimYamlRuleEnd returns the line after the rule ending before the end line, without the comments and blank lines
+// after it, which belong to what follows.
+func trimYamlRuleEnd(lines []string, start, end int) int {
+	for end > start+1 && isYamlBlankOrComment(lines[end-1]) {
+		end--
+	}
+	return end
+}
+
+// spliceYamlAppend adds the rules after the last rule of a yaml document, with its indent.
+func spliceYamlAppend(content []byte, rules []IgnoreRule) ([]byte, bool, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+	starts, end, indent, ok := getYamlRuleLines(content, lines)
+	if !ok {
+		return nil, false, nil
+	}
+	end = trimYamlRuleEnd(lines, starts[len(starts)-1], end)
+
+	b := &bytes.Buffer{}
+	e := yaml.NewEncoder(b)
+	e.SetIndent(2)
+	if err := e.Encode(rules); err != nil {
+		return nil, false, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, false, err
+	}
+	var text []string
+	if !strings.HasSuffix(lines[end-1], "\n") {
+		text = append(text, "\n")
+	}
+	for _, line := range strings.SplitAfter(b.String(), "\n") {
+		if line != "" {
+			text = append(text, indent+line)
+		}
+	}
+	return []byte(strings.Join(lines[:end], "") + strings.Join(text, "") + strings.Join(lines[end:], "")), true, nil
+}
+
+// spliceYamlRemove removes the lines of the rule at the index of a yaml document, with the comment lines directly
+// above it. The last rule is not removed this way, as an empty block list is no list.
+func spliceYamlRemove(content []byte, index int) ([]byte, bool) {
+	lines := strings.SplitAfter(string(content), "\n")
+	starts, end, indent, ok := getYamlRuleLines(content, lines)
+	if !ok || len(starts) < 2 || index >= len(starts) {
+		return nil, false
+	}
+	if index+1 < len(starts) {
+		end = starts[index+1]
+	}
+	end = trimYamlRuleEnd(lines, starts[index], end)
+	// the comment lines directly above a rule describe it, but those above the first rule of a document which is a
+	// list describe the document
+	start, first := starts[index], 0
+	if index > 0 {
+		first = starts[index-1] + 1
+	} else if indent == "" {
+		first = start
+	}
+	for start > first && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "#") {
+		start--
+	}
+	return []byte(strings.Join(lines[:start], "") + strings.Join(lines[end:], "")), true
+}
+
+// addIgnoreRules appends the rules to an ignores file, keeping the other bytes. A missing ignores file is created.
+func addIgnoreRules(ignoreListFile string, rules []IgnoreRule) error {
+	content, err := os.ReadFile(ignoreListFile)
+	if err == nil {
+		splice := spliceJsoncAppend
+		if getIgnoreFileFormat(ignoreListFile) == formatYaml {
+			splice = spliceYamlAppend
+		}
+		spliced, ok, err := splice(content, rules)
+		if err != nil {
+			return err
+		} else if ok {
+			return os.WriteFile(ignoreListFile, spliced, 0644)
+		}
+	}
+	return updateIgnoresFile(ignoreListFile, func(doc *yaml.Node) error {
+		return appendIgnoreRules(doc, rules)
+	})
+}
+
+// removeIgnoreRule removes the rule at the index from an ignores file, keeping the other bytes.
+func removeIgnoreRule(ignoreListFile string, index int) error {
+	content, err := os.ReadFile(ignoreListFile)
+	if err != nil {
+		return err
+	}
+	splice := spliceJsoncRemove
+	if getIgnoreFileFormat(ignoreListFile) == formatYaml {
+		splice = spliceYamlRemove
+	}
+	if spliced, ok := splice(content, index); ok {
+		return os.WriteFile(ignoreListFile, spliced, 0644)
+	}
+	return updateIgnoresFile(ignoreListFile, func(doc *yaml.Node) error {
+		rulesNode, err := getRulesNode(doc)
+		if err != nil {
+			return err
+		}
+		rulesNode.Content = append(rulesNode.Content[:index], rulesNode.Content[index+1:]...)
+		return nil
+	})
+}
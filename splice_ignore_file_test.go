@@ -0,0 +1,194 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsoncToSplice = `/* shared */ {"include": ["a.json"],   "rules": [
+    // partner site is down
+    {"url": "https://partner.com/", "error": "503"}, // until March
+      {"url": "b",
+       "error": "404"} /* b */
+  ]   , "expectations": [] }
+`
+
+func TestSpliceJsoncAppend(t *testing.T) {
+	spliced, ok, err := spliceJsoncAppend([]byte(jsoncToSplice), []IgnoreRule{{Url: "c", Error: "403"}})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `/* shared */ {"include": ["a.json"],   "rules": [
+    // partner site is down
+    {"url": "https://partner.com/", "error": "503"}, // until March
+      {"url": "b",
+       "error": "404"}, /* b */
+      {
+        "url": "c",
+        "error": "403"
+      }
+  ]   , "expectations": [] }
+`, string(spliced))
+
+	// a trailing comma is kept
+	spliced, ok, err = spliceJsoncAppend([]byte("[\n  {\"url\": \"a\"}, // a\n]"), []IgnoreRule{{Url: "b"}, {Url: "c"}})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "[\n  {\"url\": \"a\"}, // a\n  {\n    \"url\": \"b\"\n  },\n  {\n    \"url\": \"c\"\n  },\n]", string(spliced))
+
+	// an empty list
+	spliced, ok, err = spliceJsoncAppend([]byte("// none yet\n{\n  \"rules\": [ ] // todo\n}\n"), []IgnoreRule{{Url: "a"}})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "// none yet\n{\n  \"rules\": [\n    {\n      \"url\": \"a\"\n    }\n  ] // todo\n}\n", string(spliced))
+
+	// rules on the line of the list are not spliced
+	for _, content := range []string{`[{"url": "a"}]`, "[\n  {\"url\": \"a\"}, {\"url\": \"b\"} /* b\n */\n]", "{}", "[\n  /* none */\n]"} {
+		_, ok, err = spliceJsoncAppend([]byte(content), []IgnoreRule{{Url: "c"}})
+		assert.Nil(t, err)
+		assert.False(t, ok, content)
+	}
+}
+
+func TestSpliceJsoncRemove(t *testing.T) {
+	spliced, ok := spliceJsoncRemove([]byte(jsoncToSplice), 0)
+	assert.True(t, ok)
+	assert.Equal(t, `/* shared */ {"include": ["a.json"],   "rules": [
+      {"url": "b",
+       "error": "404"} /* b */
+  ]   , "expectations": [] }
+`, string(spliced))
+
+	// the rule before the removed last rule is the last one now
+	spliced, ok = spliceJsoncRemove([]byte(jsoncToSplice), 1)
+	assert.True(t, ok)
+	assert.Equal(t, `/* shared */ {"include": ["a.json"],   "rules": [
+    // partner site is down
+    {"url": "https://partner.com/", "error": "503"} // until March
+  ]   , "expectations": [] }
+`, string(spliced))
+
+	// a trailing comma is removed with its rule
+	spliced, ok = spliceJsoncRemove([]byte("[\n  {\"url\": \"a\"},\n  {\"url\": \"b\"},\n]"), 1)
+	assert.True(t, ok)
+	assert.Equal(t, "[\n  {\"url\": \"a\"},\n]", string(spliced))
+
+	_, ok = spliceJsoncRemove([]byte(`[{"url": "a"}, {"url": "b"}]`), 1)
+	assert.False(t, ok)
+}
+
+const yamlToSplice = `# shared
+include: [a.yaml]
+rules:
+  # partner site is down
+  - url: https://partner.com/
+    error: "503" # until March
+
+  # blog
+  - url: b
+    error: "404"
+  # end of rules
+
+expectations: []
+`
+
+func TestSpliceYamlAppend(t *testing.T) {
+	spliced, ok, err := spliceYamlAppend([]byte(yamlToSplice), []IgnoreRule{{Url: "c", Error: "403"}})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `# shared
+include: [a.yaml]
+rules:
+  # partner site is down
+  - url: https://partner.com/
+    error: "503" # until March
+
+  # blog
+  - url: b
+    error: "404"
+  - url: c
+    error: "403"
+  # end of rules
+
+expectations: []
+`, string(spliced))
+
+	spliced, ok, err = spliceYamlAppend([]byte("- url: a"), []IgnoreRule{{Url: "b"}})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "- url: a\n- url: b\n", string(spliced))
+
+	for _, content := range []string{"[{url: a}]", "rules: []", "{}"} {
+		_, ok, err = spliceYamlAppend([]byte(content), []IgnoreRule{{Url: "c"}})
+		assert.Nil(t, err)
+		assert.False(t, ok, content)
+	}
+}
+
+func TestSpliceYamlRemove(t *testing.T) {
+	spliced, ok := spliceYamlRemove([]byte(yamlToSplice), 1)
+	assert.True(t, ok)
+	assert.Equal(t, `# shared
+include: [a.yaml]
+rules:
+  # partner site is down
+  - url: https://partner.com/
+    error: "503" # until March
+
+  # end of rules
+
+expectations: []
+`, string(spliced))
+
+	spliced, ok = spliceYamlRemove([]byte(yamlToSplice), 0)
+	assert.True(t, ok)
+	assert.Equal(t, `# shared
+include: [a.yaml]
+rules:
+
+  # blog
+  - url: b
+    error: "404"
+  # end of rules
+
+expectations: []
+`, string(spliced))
+
+	// the comments above the first rule of a list describe the document
+	spliced, ok = spliceYamlRemove([]byte("# shared\n- url: a\n# b\n- url: b\n"), 0)
+	assert.True(t, ok)
+	assert.Equal(t, "# shared\n# b\n- url: b\n", string(spliced))
+
+	// an empty block list is no list
+	_, ok = spliceYamlRemove([]byte("- url: a\n"), 0)
+	assert.False(t, ok)
+}
+
+func TestAddAndRemoveIgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+
+	// a missing file is created
+	ignoreListFile := filepath.Join(dir, "ignores.yaml")
+	assert.Nil(t, addIgnoreRules(ignoreListFile, []IgnoreRule{{Url: "a"}, {Url: "b"}}))
+	rules, err := readIgnoreRules(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, []IgnoreRule{{Url: "a"}, {Url: "b"}}, rules)
+
+	// the last rule is removed from the document
+	assert.Nil(t, removeIgnoreRule(ignoreListFile, 1))
+	assert.Nil(t, removeIgnoreRule(ignoreListFile, 0))
+	rules, err = readIgnoreRules(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+
+	// layouts which are not spliced are written again
+	ignoreListFile = filepath.Join(dir, "ignores.json")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`[{"url": "a"}, {"url": "b"}]`), 0644))
+	assert.Nil(t, removeIgnoreRule(ignoreListFile, 0))
+	assert.Nil(t, addIgnoreRules(ignoreListFile, []IgnoreRule{{Url: "c"}}))
+	content, err := os.ReadFile(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "[\n  {\n    \"url\": \"b\"\n  },\n  {\n    \"url\": \"c\"\n  }\n]\n", string(content))
+}
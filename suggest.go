@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type suggestArguments struct {
+	arguments
+	Json      bool `long:"json" description:"Show the suggested rules in json format"`
+	HostRules bool `long:"host-rules" description:"Suggest one rule for all links to a host, instead of one rule per path, when the links spread over many top level paths"`
+}
+
+const suggestUsage = "suggest [options] <url of website to check>"
+
+// with --host-rules, a host-wide rule is suggested, instead of one rule per path, when the links spread over this many
+// top level paths
+const minPathsForHostRule = 3
+
+// suggestion is a proposed ignore rule, with the number of broken links and pages it covers.
+type suggestion struct {
+	Rule  IgnoreRule `json:"rule"`
+	Links int        `json:"links"`
+	Pages int        `json:"pages"`
+	// Broad suggestions match every url of a host, and should be reviewed before use
+	Broad   bool   `json:"broad,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// suggestCluster holds broken links to the same host, with the same kind of error.
+type suggestCluster struct {
+	host      string
+	errorRule IgnoreRule
+	links     []pageErrorLink
+	hostRules bool
+}
+
+// getErrorRule returns the error part of a suggested rule for the link: the status code of http errors, the error
+// message of uncategorized errors, or else the error category.
+func getErrorRule(link UrlErrorLink) (key string, rule IgnoreRule) {
+	category, status := classifyError(link.Error)
+	switch category {
+	case categoryHttpStatus:
+		rule.Status = fmt.Sprint(status)
+		return "status:" + rule.Status, rule
+	case categoryOther:
+		// the redirect target is not part of the rule, so the rule also matches redirected links
+		message := followingRedirectSuffix.ReplaceAllString(link.Error, "")
+		rule.Error = "^" + regexp.QuoteMeta(message)
+		return "error:" + message, rule
+	default:
+		rule.Category = string(category)
+		return "category:" + rule.Category, rule
+	}
+}
+
+// getQuerylessUrl returns the url without query and fragment.
+func getQuerylessUrl(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.EscapedPath()
+}
+
+func getPathSegments(u *url.URL) []string {
+	path := strings.Trim(u.EscapedPath(), "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// getSuggestions clusters the broken links by host and error, and proposes the smallest set of rules for them.
+// Rules matching every url of a host are only proposed if hostRules is set.
+func getSuggestions(errorLinks []pageErrorLink, hostRules bool) (suggestions []suggestion) {
+	clusters := map[string]*suggestCluster{}
+	var keys []string
+	for _, errorLink := range errorLinks {
+		errorKey, errorRule := getErrorRule(errorLink.Link)
+		host := ""
+		if u, err := url.Parse(errorLink.Link.Url); err == nil {
+			host = u.Host
+		}
+		key := host + " " + errorKey
+		if clusters[key] == nil {
+			clusters[key] = &suggestCluster{host: host, errorRule: errorRule, hostRules: hostRules}
+			keys = append(keys, key)
+		}
+		clusters[key].links = append(clusters[key].links, errorLink)
+	}
+
+	for _, key := range keys {
+		for _, rule := range clusters[key].getRules(0) {
+			suggestions = append(suggestions, newSuggestion(rule, clusters[key].host, errorLinks))
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Links > suggestions[j].Links
+	})
+	return
+}
+
+// getRules proposes rules for the links of the cluster, which share the first depth path segments.
+func (cluster *suggestCluster) getRules(depth int) []IgnoreRule {
+	newRule := func(urlPattern string) []IgnoreRule {
+		rule := cluster.errorRule
+		rule.Url = urlPattern
+		return []IgnoreRule{rule}
+	}
+
+	var urls []*url.URL
+	distinct := map[string]bool{}
+	if cluster.host == "" {
+		// links without a host are not urls we can generalize, so ignore exactly each link
+		var rules []IgnoreRule
+		for _, errorLink := range cluster.links {
+			if !distinct[errorLink.Link.Url] {
+				distinct[errorLink.Link.Url] = true
+				rules = append(rules, newRule(literalPattern(errorLink.Link.Url))...)
+			}
+		}
+		return rules
+	}
+	for _, errorLink := range cluster.links {
+		// the links of a cluster with a host are valid urls
+		u, _ := url.Parse(errorLink.Link.Url)
+		if !distinct[getQuerylessUrl(u)] {
+			distinct[getQuerylessUrl(u)] = true
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 1 {
+		// the same url, with any query or fragment
+		return newRule("^" + regexp.QuoteMeta(getQuerylessUrl(urls[0])) + "([?#].*)?$")
+	}
+
+	prefix := getPathSegments(urls[0])
+	for _, u := range urls[1:] {
+		segments := getPathSegments(u)
+		i := 0
+		for ; i < len(prefix) && i < len(segments) && prefix[i] == segments[i]; i++ {
+		}
+		prefix = prefix[:i]
+	}
+	if len(prefix) > depth {
+		return newRule(prefixPattern(cluster.host, prefix))
+	}
+
+	// split the cluster by the next path segment
+	var subKeys []string
+	subClusters := map[string]*suggestCluster{}
+	for _, errorLink := range cluster.links {
+		u, _ := url.Parse(errorLink.Link.Url)
+		segment := ""
+		if segments := getPathSegments(u); len(segments) > depth {
+			segment = segments[depth]
+		}
+		if subClusters[segment] == nil {
+			subClusters[segment] = &suggestCluster{host: cluster.host, errorRule: cluster.errorRule, hostRules: cluster.hostRules}
+			subKeys = append(subKeys, segment)
+		}
+		subClusters[segment].links = append(subClusters[segment].links, errorLink)
+	}
+	if len(subKeys) == 1 && len(prefix) == 0 {
+		// the root of the host, with any query or fragment
+		return newRule("^https?://" + regexp.QuoteMeta(cluster.host) + "/?([?#].*)?$")
+	}
+	if cluster.hostRules && depth == 0 && len(subKeys) >= minPathsForHostRule || len(subKeys) == 1 {
+		return newRule(prefixPattern(cluster.host, prefix))
+	}
+	var rules []IgnoreRule
+	for _, segment := range subKeys {
+		rules = append(rules, subClusters[segment].getRules(depth+1)...)
+	}
+	return rules
+}
+
+// prefixPattern returns a pattern matching the urls of the host below the path, or every url of the host without path.
+func prefixPattern(host string, path []string) string {
+	if len(path) == 0 {
+		return hostPattern("https://" + host)
+	}
+	return "^https?://" + regexp.QuoteMeta(host) + "/" + regexp.QuoteMeta(strings.Join(path, "/")) + "([/?#]|$)"
+}
+
+func newSuggestion(rule IgnoreRule, host string, errorLinks []pageErrorLink) suggestion {
+	s := suggestion{Rule: rule}
+	pages := map[string]bool{}
+	for _, errorLink := range errorLinks {
+		if rule.isMatch(errorLink.Page, errorLink.Link) {
+			s.Links++
+			pages[errorLink.Page] = true
+		}
+	}
+	s.Pages = len(pages)
+	if host != "" && rule.Url == hostPattern("https://"+host) {
+		s.Broad = true
+		s.Warning = "matches every url on " + host + ", including links broken in the future"
+	}
+	return s
+}
+
+func (s *suggestion) String() string {
+	parts := []string{"url: " + s.Rule.Url}
+	if s.Rule.Error != "" {
+		parts = append(parts, "error: "+s.Rule.Error)
+	}
+	if s.Rule.Status != "" {
+		parts = append(parts, "status: "+s.Rule.Status)
+	}
+	if s.Rule.Category != "" {
+		parts = append(parts, "category: "+s.Rule.Category)
+	}
+	text := fmt.Sprintf("%d links on %d pages: %s", s.Links, s.Pages, strings.Join(parts, ", "))
+	if s.Broad {
+		text += "\n  warning, broad rule: " + s.Warning
+	}
+	return text
+}
+
+// runSuggest checks the website, or reads the report given via --input-json, and suggests generalized rules for
+// the broken links, which are not ignored yet.
+func (c *commandFilter) runSuggest(ss []string) (bool, error) {
+	args := suggestArguments{}
+	if err := parseCommandArguments(&args, &args.arguments, suggestUsage, ss); err != nil {
+		return false, err
+	} else if args.Help || args.Version {
+		c.print(commandHelp(&suggestArguments{}, suggestUsage))
+		return true, nil
+	}
+
+	reportFiltered, err := c.checkAndFilter(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+
+	suggestions := getSuggestions(reportFiltered.errorLinks(), args.HostRules)
+	if args.Json {
+		prettyJson, err := json.MarshalIndent(suggestions, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		c.print(string(prettyJson))
+		return true, nil
+	}
+	if len(suggestions) == 0 {
+		c.print("no broken links, nothing to suggest")
+	}
+	for _, s := range suggestions {
+		c.print(s.String())
+	}
+	return true, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPageErrorLinks(page, errorText string, urls ...string) (errorLinks []pageErrorLink) {
+	for _, u := range urls {
+		errorLinks = append(errorLinks, pageErrorLink{Page: page, Link: UrlErrorLink{Url: u, Error: errorText}})
+	}
+	return
+}
+
+func TestGetSuggestionsPathPrefix(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "403",
+		"https://learn.sonatype.com/courses/iq-100/",
+		"https://learn.sonatype.com/courses/iq-101/")
+	errorLinks = append(errorLinks, newPageErrorLinks("https://my-site.com/b", "403",
+		"https://learn.sonatype.com/courses/iq-101/")...)
+
+	suggestions := getSuggestions(errorLinks, false)
+	assert.Equal(t, []suggestion{{
+		Rule:  IgnoreRule{Url: `^https?://learn\.sonatype\.com/courses([/?#]|$)`, Status: "403"},
+		Links: 3,
+		Pages: 2,
+	}}, suggestions)
+}
+
+func TestGetSuggestionsSameUrl(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "id #content-wrapper not found",
+		"https://help.sonatype.com/index.html#content-wrapper",
+		"https://help.sonatype.com/index.html?q=1#content-wrapper")
+
+	suggestions := getSuggestions(errorLinks, false)
+	assert.Equal(t, 1, len(suggestions))
+	assert.Equal(t, IgnoreRule{
+		Url:      `^https://help\.sonatype\.com/index\.html([?#].*)?$`,
+		Category: "fragment-not-found",
+	}, suggestions[0].Rule)
+	assert.Equal(t, 2, suggestions[0].Links)
+	assert.False(t, suggestions[0].Broad)
+}
+
+func TestGetSuggestionsSplitsByErrorAndPath(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "404",
+		"https://github.com/org/a/issues/1",
+		"https://github.com/org/a/issues/2",
+		"https://github.com/other/b")
+	errorLinks = append(errorLinks, newPageErrorLinks("https://my-site.com/a", "timeout",
+		"https://github.com/org/a")...)
+	errorLinks = append(errorLinks, newPageErrorLinks("https://my-site.com/a",
+		"body size exceeds the given limit (following redirect https://cdn.com/file)",
+		"https://github.com/org/a/releases/file.zip")...)
+
+	suggestions := getSuggestions(errorLinks, false)
+	var rules []IgnoreRule
+	for _, s := range suggestions {
+		rules = append(rules, s.Rule)
+	}
+	assert.Equal(t, []IgnoreRule{
+		{Url: `^https?://github\.com/org/a/issues([/?#]|$)`, Status: "404"},
+		{Url: `^https://github\.com/other/b([?#].*)?$`, Status: "404"},
+		{Url: `^https://github\.com/org/a([?#].*)?$`, Category: "timeout"},
+		{Url: `^https://github\.com/org/a/releases/file\.zip([?#].*)?$`, Error: `^body size exceeds the given limit`},
+	}, rules)
+}
+
+func TestGetSuggestionsWithoutHost(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "404",
+		"mailto:info@my-site.com",
+		"/relative/path",
+		"%zz",
+		"/relative/path")
+
+	suggestions := getSuggestions(errorLinks, false)
+	var rules []IgnoreRule
+	for _, s := range suggestions {
+		rules = append(rules, s.Rule)
+	}
+	assert.Equal(t, []IgnoreRule{
+		{Url: `^/relative/path$`, Status: "404"},
+		{Url: `^mailto:info@my-site\.com$`, Status: "404"},
+		{Url: `^%zz$`, Status: "404"},
+	}, rules)
+	assert.Equal(t, 2, suggestions[0].Links)
+}
+
+func TestGetSuggestionsHostRoot(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "403",
+		"https://search.maven.org",
+		"http://search.maven.org/")
+
+	suggestions := getSuggestions(errorLinks, false)
+	assert.Equal(t, 1, len(suggestions))
+	assert.Equal(t, `^https?://search\.maven\.org/?([?#].*)?$`, suggestions[0].Rule.Url)
+	assert.False(t, suggestions[0].Broad)
+	assert.False(t, isPatternMatch(suggestions[0].Rule.Url, "https://search.maven.org/stats"))
+}
+
+func TestGetSuggestionsBroad(t *testing.T) {
+	errorLinks := newPageErrorLinks("https://my-site.com/a", "404",
+		"https://github.com/a/x",
+		"https://github.com/b/x",
+		"https://github.com/c/x")
+
+	// one rule per path by default
+	suggestions := getSuggestions(errorLinks, false)
+	assert.Equal(t, 3, len(suggestions))
+	for _, s := range suggestions {
+		assert.False(t, s.Broad)
+	}
+
+	suggestions = getSuggestions(errorLinks, true)
+	assert.Equal(t, 1, len(suggestions))
+	assert.Equal(t, `^https?://github\.com([/?#]|$)`, suggestions[0].Rule.Url)
+	assert.True(t, suggestions[0].Broad)
+	assert.Contains(t, suggestions[0].String(), "warning, broad rule: matches every url on github.com")
+}
+
+func TestSuggest(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{})
+
+	ok := cf.Run([]string{"suggest", "-i", "testdata/urlErrorIgnore.json", "-j", "testdata/reportErrorsOnly.json"})
+	assert.True(t, ok)
+	assert.Empty(t, stderr.String())
+	assert.NotContains(t, stdout.String(), "warning, broad rule")
+
+	stdout.Reset()
+	ok = cf.Run([]string{"suggest", "--host-rules", "-i", "testdata/urlErrorIgnore.json", "-j", "testdata/reportErrorsOnly.json"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), `496 links on 8 pages: url: ^https?://download\.sonatype\.com([/?#]|$), error: ^body size exceeds the given limit`)
+
+	stdout.Reset()
+	ok = cf.Run([]string{"suggest", "--json", "--host-rules", "-i", "testdata/urlErrorIgnore.json", "-j", "testdata/reportErrorsOnly.json"})
+	assert.True(t, ok)
+	var suggestions []suggestion
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &suggestions))
+	assert.Equal(t, 496, suggestions[0].Links)
+	assert.True(t, suggestions[0].Broad)
+
+	stdout.Reset()
+	ok = cf.Run([]string{"suggest", "--help"})
+	assert.True(t, ok)
+	assert.Contains(t, stdout.String(), "--json")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+type triageArguments struct {
+	arguments
+}
+
+const triageUsage = "triage [options] <url of website to check>"
+
+// triageProposal is an ignore rule offered for a broken link during triage.
+type triageProposal struct {
+	key         string
+	description string
+	rule        IgnoreRule
+}
+
+// getTriageProposals returns the rules offered for a broken link: ignore the exact link, ignore the error on any
+// link to the same host, or ignore the error on any link. If scopeToPage is set, the rules only apply on the page.
+func getTriageProposals(errorLink pageErrorLink, scopeToPage bool, reason string) []triageProposal {
+	link := errorLink.Link
+	proposals := []triageProposal{
+		{"e", "ignore this exact link", IgnoreRule{Url: literalPattern(link.Url), Error: literalPattern(link.Error)}},
+		{"h", "ignore this error on any link to the host", IgnoreRule{Url: hostPattern(link.Url), Error: literalPattern(link.Error)}},
+		{"r", "ignore this error on any link", IgnoreRule{Url: ".*", Error: literalPattern(link.Error)}},
+	}
+	for i := range proposals {
+		if scopeToPage {
+			proposals[i].rule.Page = literalPattern(errorLink.Page)
+		}
+		proposals[i].rule.Reason = reason
+	}
+	return proposals
+}
+
+// countOtherMatches counts the other links, which would also be ignored by the rule, and not by the rules added so far.
+func countOtherMatches(rule IgnoreRule, current int, errorLinks []pageErrorLink, added []IgnoreRule) (count int) {
+	for i, other := range errorLinks {
+		if i != current && rule.isMatch(other.Page, other.Link) && !isErrorIgnored(other.Page, other.Link, added) {
+			count++
+		}
+	}
+	return
+}
+
+// runTriage checks the website, and walks through the remaining broken links. For each link, the user can choose
+// an ignore rule, which is added to the ignores file.
+func (c *commandFilter) runTriage(ss []string) (bool, error) {
+	args := triageArguments{}
+	if err := parseCommandArguments(&args, &args.arguments, triageUsage, ss); err != nil {
+		return false, err
+	} else if args.Help || args.Version {
+		c.print(commandHelp(&triageArguments{}, triageUsage))
+		return true, nil
+	}
+
+	ignoreListFile, err := getIgnoresFileToEdit(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+	allowNewIgnoresFile(&args.arguments, ignoreListFile)
+	reportFiltered, err := c.checkAndFilter(&args.arguments)
+	if err != nil {
+		return false, err
+	}
+
+	errorLinks := reportFiltered.errorLinks()
+	if len(errorLinks) == 0 {
+		c.print("no broken links to triage")
+		return true, nil
+	}
+
+	input := bufio.NewScanner(c.stdin)
+	var added []IgnoreRule
+	isIgnored := func(errorLink pageErrorLink) bool {
+		return isErrorIgnored(errorLink.Page, errorLink.Link, added)
+	}
+
+triage:
+	for i, errorLink := range errorLinks {
+		if isIgnored(errorLink) {
+			continue
+		}
+		scopeToPage := false
+		reason := ""
+		for {
+			proposals := getTriageProposals(errorLink, scopeToPage, reason)
+			c.printTriageLink(i, errorLinks, added, scopeToPage, reason, proposals)
+
+			if !input.Scan() {
+				break triage
+			}
+			choice := strings.TrimSpace(input.Text())
+			switch choice {
+			case "p":
+				scopeToPage = !scopeToPage
+				continue
+			case "c":
+				c.print("reason: ")
+				if !input.Scan() {
+					break triage
+				}
+				reason = strings.TrimSpace(input.Text())
+				continue
+			case "s":
+				continue triage
+			case "q":
+				break triage
+			}
+			for _, proposal := range proposals {
+				if proposal.key == choice {
+					added = append(added, proposal.rule)
+					c.print("added rule: url: ", proposal.rule.Url, ", error: ", proposal.rule.Error)
+					continue triage
+				}
+			}
+			c.print("unknown choice: ", choice)
+		}
+	}
+
+	if len(added) == 0 {
+		c.print("no rules added")
+		return true, nil
+	}
+	if err = addIgnoreRules(ignoreListFile, added); err != nil {
+		return false, err
+	}
+	c.print(fmt.Sprintf("added %d rules to: %s", len(added), ignoreListFile))
+	return true, nil
+}
+
+func (c *commandFilter) printTriageLink(current int, errorLinks []pageErrorLink, added []IgnoreRule, scopeToPage bool, reason string, proposals []triageProposal) {
+	errorLink := errorLinks[current]
+	category, _ := classifyError(errorLink.Link.Error)
+	lines := []string{
+		fmt.Sprintf("[%d/%d] page: %s", current+1, len(errorLinks), errorLink.Page),
+		fmt.Sprintf("  link:  %s", errorLink.Link.Url),
+		fmt.Sprintf("  error: %s (%s)", errorLink.Link.Error, category),
+	}
+	for _, proposal := range proposals {
+		lines = append(lines, fmt.Sprintf("  %s) %s, url: %s (also ignores %d other links)",
+			proposal.key, proposal.description, proposal.rule.Url, countOtherMatches(proposal.rule, current, errorLinks, added)))
+	}
+	scope := "off"
+	if scopeToPage {
+		scope = "on"
+	}
+	lines = append(lines,
+		fmt.Sprintf("  p) only ignore on this page [%s]", scope),
+		fmt.Sprintf("  c) add a reason [%s]", reason),
+		"  s) skip this link",
+		"  q) quit, and save the rules added so far",
+		"> ",
+	)
+	c.print(strings.Join(lines, "\n"))
+}
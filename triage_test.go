@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonReportTriage = `[
+  {
+    "url": "https://my-site.com/",
+    "links": [
+      {"url": "https://www.linkedin.com/company/foo", "error": "999"},
+      {"url": "https://cdn.example.com/a.js", "error": "403"},
+      {"url": "https://cdn.example.com/b.js", "error": "403"}
+    ]
+  },
+  {
+    "url": "https://my-site.com/about.html",
+    "links": [
+      {"url": "https://www.linkedin.com/in/bar", "error": "999"},
+      {"url": "https://my-site.com/#top", "error": "id #top not found"}
+    ]
+  }
+]`
+
+func TestGetTriageProposals(t *testing.T) {
+	errorLink := pageErrorLink{"https://my-site.com/", UrlErrorLink{"https://cdn.example.com/a.js", "403"}}
+	proposals := getTriageProposals(errorLink, false, "")
+	assert.Equal(t, IgnoreRule{Url: `^https://cdn\.example\.com/a\.js$`, Error: "^403$"}, proposals[0].rule)
+	assert.Equal(t, IgnoreRule{Url: `^https?://cdn\.example\.com([/?#]|$)`, Error: "^403$"}, proposals[1].rule)
+	assert.Equal(t, IgnoreRule{Url: ".*", Error: "^403$"}, proposals[2].rule)
+
+	proposals = getTriageProposals(errorLink, true, "cdn blocks crawlers")
+	for _, proposal := range proposals {
+		assert.Equal(t, `^https://my-site\.com/$`, proposal.rule.Page)
+		assert.Equal(t, "cdn blocks crawlers", proposal.rule.Reason)
+	}
+}
+
+func TestCountOtherMatches(t *testing.T) {
+	report, err := (&parseResponse{jsonReportTriage}).loadReport(&arguments{})
+	assert.Nil(t, err)
+	errorLinks := report.errorLinks()
+	assert.Equal(t, 5, len(errorLinks))
+
+	proposals := getTriageProposals(errorLinks[1], false, "")
+	assert.Equal(t, 0, countOtherMatches(proposals[0].rule, 1, errorLinks, nil))
+	assert.Equal(t, 1, countOtherMatches(proposals[1].rule, 1, errorLinks, nil))
+	assert.Equal(t, 1, countOtherMatches(proposals[2].rule, 1, errorLinks, nil))
+	// links ignored by rules added before are not counted
+	assert.Equal(t, 0, countOtherMatches(proposals[2].rule, 1, errorLinks, []IgnoreRule{{Url: "b.js", Error: "403"}}))
+
+	proposals = getTriageProposals(errorLinks[0], true, "")
+	assert.Equal(t, 0, countOtherMatches(proposals[2].rule, 0, errorLinks, nil))
+}
+
+func runTriageTest(t *testing.T, ignoreListFile, input string) (bool, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	mockExec := &mockMuffetExecutor{result: jsonReportTriage}
+	cf := newCommandFilter(stdout, stderr, false, &mockMuffetFactory{executor: mockExec})
+	cf.stdin = strings.NewReader(input)
+
+	ok := cf.Run([]string{"triage", "-i", ignoreListFile, "https://my-site.com/"})
+	assert.Empty(t, stderr.String())
+	return ok, stdout.String()
+}
+
+func TestTriage(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.jsonc")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`// shared by the docs team
+[
+  // fragments are generated by javascript
+  {"url": "https://my-site.com/#top", "error": "id #top not found"}
+]
+`), 0644))
+
+	// link 1: add a reason, ignore linkedin errors on any link, which also covers link 4
+	// link 2: scope to the page, ignore the host
+	// link 3: covered by the rule for link 2
+	ok, output := runTriageTest(t, ignoreListFile, "c\nlinkedin blocks crawlers\nr\np\nh\n")
+	assert.True(t, ok)
+	assert.Contains(t, output, "[1/4] page: https://my-site.com/")
+	assert.Contains(t, output, "error: 999 (http-status)")
+	assert.Contains(t, output, "r) ignore this error on any link, url: .* (also ignores 1 other links)")
+	assert.Contains(t, output, "c) add a reason [linkedin blocks crawlers]")
+	assert.Contains(t, output, "p) only ignore on this page [on]")
+	assert.NotContains(t, output, "[3/4]")
+	assert.NotContains(t, output, "[4/4]")
+	assert.Contains(t, output, "added 2 rules to: "+ignoreListFile)
+
+	content, err := os.ReadFile(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, `// shared by the docs team
+[
+  // fragments are generated by javascript
+  {"url": "https://my-site.com/#top", "error": "id #top not found"},
+  {
+    "url": ".*",
+    "error": "^999$",
+    "reason": "linkedin blocks crawlers"
+  },
+  {
+    "url": "^https?://cdn\\.example\\.com([/?#]|$)",
+    "error": "^403$",
+    "page": "^https://my-site\\.com/$"
+  }
+]
+`, string(content))
+
+	// the added rules are used by the next run
+	ok, output = runTriageTest(t, ignoreListFile, "")
+	assert.True(t, ok)
+	assert.Contains(t, output, "no broken links to triage")
+}
+
+func TestTriageSkipAndQuit(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+
+	ok, output := runTriageTest(t, ignoreListFile, "x\ns\ne\nq\n")
+	assert.True(t, ok)
+	assert.Contains(t, output, "unknown choice: x")
+	assert.Contains(t, output, "[2/5] page: https://my-site.com/")
+	assert.Contains(t, output, "[3/5] page: https://my-site.com/")
+	assert.NotContains(t, output, "[4/5]")
+
+	content, err := os.ReadFile(ignoreListFile)
+	assert.Nil(t, err)
+	assert.Equal(t, `- url: ^https://cdn\.example\.com/a\.js$
+  error: ^403$
+`, string(content))
+}
+
+func TestTriageNoRulesAdded(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+
+	// end of input quits
+	ok, output := runTriageTest(t, ignoreListFile, "s\n")
+	assert.True(t, ok)
+	assert.Contains(t, output, "no rules added")
+	itExists, _ := doesFileExist(ignoreListFile)
+	assert.False(t, itExists)
+}
+
+func TestTriageRemoteIgnoresFile(t *testing.T) {
+	cf := newCommandFilter(&bytes.Buffer{}, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"triage", "-i", "https://example.com/ignores.json", "https://my-site.com/"})
+	assert.False(t, ok)
+	assert.EqualError(t, err, "cannot change remote ignores file: https://example.com/ignores.json")
+}
+
+func TestTriageNewIgnoresFile(t *testing.T) {
+	// the project ignores file is not used when another, new, ignores file is given
+	projectDir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectDir, ".git"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectDir, configDir), 0755))
+	assert.Nil(t, os.WriteFile(getDefaultIgnoresFile(projectDir), []byte(`[{"url": "linkedin", "error": "999"}]`), 0644))
+	t.Chdir(projectDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(orgIgnoresEnvVar, "")
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.json")
+
+	ok, output := runTriageTest(t, ignoreListFile, "q\n")
+	assert.True(t, ok)
+	assert.Contains(t, output, "[1/5] page: https://my-site.com/")
+	assert.Contains(t, output, "link:  https://www.linkedin.com/company/foo")
+}
+
+func TestTriageHelp(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	cf := newCommandFilter(stdout, &bytes.Buffer{}, false, &mockMuffetFactory{})
+	ok, err := cf.runWithError([]string{"triage", "--help"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), triageUsage)
+	assert.NotContains(t, stdout.String(), "Commands:")
+}
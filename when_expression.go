@@ -0,0 +1,517 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// A when expression further restricts an ignore rule, e.g.
+//
+//	link.host =~ "(^|\.)linkedin\.com$" && status == 403 && !startsWith(page.path, "/careers")
+//
+// Expressions are type-checked when compiled, and can only read the variables of the broken link, so evaluating them
+// is safe and cannot fail.
+
+type whenType string
+
+const (
+	whenBool   whenType = "bool"
+	whenInt    whenType = "int"
+	whenString whenType = "string"
+)
+
+// whenVariables are the variables of the broken link, which expressions can read.
+var whenVariables = map[string]whenType{
+	"page":       whenString,
+	"page.host":  whenString,
+	"page.path":  whenString,
+	"page.query": whenString,
+	"link":       whenString,
+	"link.host":  whenString,
+	"link.path":  whenString,
+	"link.query": whenString,
+	"error":      whenString,
+	"category":   whenString,
+	"status":     whenInt,
+	"internal":   whenBool,
+	"external":   whenBool,
+}
+
+type whenFunction struct {
+	params []whenType
+	result whenType
+	call   func(args []any) any
+}
+
+var whenFunctions = map[string]whenFunction{
+	"startsWith": {[]whenType{whenString, whenString}, whenBool, func(args []any) any {
+		return strings.HasPrefix(args[0].(string), args[1].(string))
+	}},
+	"endsWith": {[]whenType{whenString, whenString}, whenBool, func(args []any) any {
+		return strings.HasSuffix(args[0].(string), args[1].(string))
+	}},
+	"contains": {[]whenType{whenString, whenString}, whenBool, func(args []any) any {
+		return strings.Contains(args[0].(string), args[1].(string))
+	}},
+	"lower": {[]whenType{whenString}, whenString, func(args []any) any {
+		return strings.ToLower(args[0].(string))
+	}},
+}
+
+// whenEnv holds the values of the variables for one broken link.
+type whenEnv map[string]any
+
+func newWhenEnv(page string, errorLink UrlErrorLink) whenEnv {
+	category, status := classifyError(errorLink.Error)
+	env := whenEnv{"page": page, "link": errorLink.Url, "error": errorLink.Error, "category": string(category), "status": status}
+	addUrlVariables(env, "page", page)
+	addUrlVariables(env, "link", errorLink.Url)
+	// links to the host of the page are internal
+	env["internal"] = env["link.host"] == env["page.host"]
+	env["external"] = !env["internal"].(bool)
+	return env
+}
+
+func addUrlVariables(env whenEnv, prefix, rawUrl string) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		u = &url.URL{}
+	}
+	env[prefix+".host"] = strings.ToLower(u.Hostname())
+	env[prefix+".path"] = u.Path
+	env[prefix+".query"] = u.RawQuery
+}
+
+type whenNode interface {
+	eval(env whenEnv) any
+}
+
+type whenLiteral struct{ value any }
+
+func (n *whenLiteral) eval(whenEnv) any { return n.value }
+
+type whenVariable struct{ name string }
+
+func (n *whenVariable) eval(env whenEnv) any { return env[n.name] }
+
+type whenCall struct {
+	function whenFunction
+	args     []whenNode
+}
+
+func (n *whenCall) eval(env whenEnv) any {
+	var args []any
+	for _, arg := range n.args {
+		args = append(args, arg.eval(env))
+	}
+	return n.function.call(args)
+}
+
+type whenNot struct{ operand whenNode }
+
+func (n *whenNot) eval(env whenEnv) any { return !n.operand.eval(env).(bool) }
+
+type whenBinary struct {
+	op          string
+	left, right whenNode
+	// regex is the compiled right operand of =~ and !~, and list the right operand of in
+	regex *regexp.Regexp
+	list  []any
+}
+
+func (n *whenBinary) eval(env whenEnv) any {
+	left := n.left.eval(env)
+	switch n.op {
+	case "&&":
+		return left.(bool) && n.right.eval(env).(bool)
+	case "||":
+		return left.(bool) || n.right.eval(env).(bool)
+	case "=~":
+		return n.regex.MatchString(left.(string))
+	case "!~":
+		return !n.regex.MatchString(left.(string))
+	case "in":
+		for _, item := range n.list {
+			if item == left {
+				return true
+			}
+		}
+		return false
+	}
+	right := n.right.eval(env)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left.(int) < right.(int)
+	case "<=":
+		return left.(int) <= right.(int)
+	case ">":
+		return left.(int) > right.(int)
+	default: // ">="
+		return left.(int) >= right.(int)
+	}
+}
+
+// whenExpression is a compiled when expression.
+type whenExpression struct {
+	root whenNode
+}
+
+func (expr *whenExpression) isMatch(page string, errorLink UrlErrorLink) bool {
+	return expr.root.eval(newWhenEnv(page, errorLink)).(bool)
+}
+
+// compiledWhenExpressions caches the compiled when expressions, like compiledPatterns.
+var compiledWhenExpressions sync.Map
+
+type compiledWhen struct {
+	expr *whenExpression
+	err  error
+}
+
+// compileWhen parses and type-checks a when expression. Errors report the position in the expression.
+func compileWhen(text string) (*whenExpression, error) {
+	if c, ok := compiledWhenExpressions.Load(text); ok {
+		return c.(compiledWhen).expr, c.(compiledWhen).err
+	}
+	expr, err := parseWhen(text)
+	compiledWhenExpressions.Store(text, compiledWhen{expr, err})
+	return expr, err
+}
+
+func parseWhen(text string) (*whenExpression, error) {
+	tokens, err := tokenizeWhen(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &whenParser{tokens: tokens}
+	root, t, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != whenEnd {
+		return nil, p.errorf(token, "unexpected %s", token)
+	}
+	if t != whenBool {
+		return nil, fmt.Errorf("expression is %s, expected bool", t)
+	}
+	return &whenExpression{root}, nil
+}
+
+type whenTokenKind int
+
+const (
+	whenEnd whenTokenKind = iota
+	whenIdent
+	whenStringToken
+	whenIntToken
+	whenOperator
+)
+
+type whenToken struct {
+	kind whenTokenKind
+	text string
+	// value is the unquoted string, or the integer
+	value any
+	// pos is the 1-based position in the expression
+	pos int
+}
+
+func (t whenToken) String() string {
+	if t.kind == whenEnd {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var whenOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func tokenizeWhen(text string) (tokens []whenToken, err error) {
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '"' || c == '\'':
+			// backslash only escapes the quote and itself, so regular expressions need no double escaping
+			var b strings.Builder
+			for i++; i < len(text) && rune(text[i]) != c; i++ {
+				if text[i] == '\\' && i+1 < len(text) && (rune(text[i+1]) == c || text[i+1] == '\\') {
+					i++
+				}
+				b.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("position %d: unterminated string", start+1)
+			}
+			i++
+			tokens = append(tokens, whenToken{whenStringToken, text[start:i], b.String(), start + 1})
+		case c >= '0' && c <= '9':
+			for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+				i++
+			}
+			n, err := strconv.Atoi(text[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("position %d: invalid number %s", start+1, text[start:i])
+			}
+			tokens = append(tokens, whenToken{whenIntToken, text[start:i], n, start + 1})
+		case c == '_' || unicode.IsLetter(c):
+			for i < len(text) && (text[i] == '_' || text[i] == '.' || unicode.IsLetter(rune(text[i])) || unicode.IsDigit(rune(text[i]))) {
+				i++
+			}
+			kind := whenIdent
+			if text[start:i] == "in" {
+				kind = whenOperator
+			}
+			tokens = append(tokens, whenToken{kind, text[start:i], nil, start + 1})
+		default:
+			operator := ""
+			for _, op := range whenOperators {
+				if strings.HasPrefix(text[i:], op) {
+					operator = op
+					break
+				}
+			}
+			if operator == "" {
+				return nil, fmt.Errorf("position %d: unexpected character %q", start+1, c)
+			}
+			i += len(operator)
+			tokens = append(tokens, whenToken{whenOperator, operator, nil, start + 1})
+		}
+	}
+	return append(tokens, whenToken{kind: whenEnd, pos: len(text) + 1}), nil
+}
+
+type whenParser struct {
+	tokens []whenToken
+	i      int
+}
+
+func (p *whenParser) peek() whenToken {
+	return p.tokens[p.i]
+}
+
+func (p *whenParser) next() whenToken {
+	token := p.tokens[p.i]
+	if token.kind != whenEnd {
+		p.i++
+	}
+	return token
+}
+
+func (p *whenParser) isOperator(op string) bool {
+	token := p.peek()
+	return token.kind == whenOperator && token.text == op
+}
+
+func (p *whenParser) expect(op string) error {
+	if token := p.next(); token.kind != whenOperator || token.text != op {
+		return p.errorf(token, "expected %q, got %s", op, token)
+	}
+	return nil
+}
+
+func (p *whenParser) errorf(token whenToken, format string, a ...any) error {
+	return fmt.Errorf("position %d: %s", token.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *whenParser) parseOr() (whenNode, whenType, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *whenParser) parseAnd() (whenNode, whenType, error) {
+	return p.parseLogical("&&", p.parseUnary)
+}
+
+func (p *whenParser) parseLogical(op string, parseOperand func() (whenNode, whenType, error)) (whenNode, whenType, error) {
+	opToken := p.peek()
+	left, t, err := parseOperand()
+	if err != nil {
+		return nil, "", err
+	}
+	for p.isOperator(op) {
+		if t != whenBool {
+			return nil, "", p.errorf(opToken, "left operand of %s is %s, expected bool", op, t)
+		}
+		opToken = p.next()
+		rightToken := p.peek()
+		var right whenNode
+		var rightType whenType
+		if right, rightType, err = parseOperand(); err != nil {
+			return nil, "", err
+		} else if rightType != whenBool {
+			return nil, "", p.errorf(rightToken, "right operand of %s is %s, expected bool", op, rightType)
+		}
+		left = &whenBinary{op: op, left: left, right: right}
+	}
+	return left, t, nil
+}
+
+func (p *whenParser) parseUnary() (whenNode, whenType, error) {
+	if p.isOperator("!") {
+		p.next()
+		operandToken := p.peek()
+		operand, t, err := p.parseUnary()
+		if err != nil {
+			return nil, "", err
+		} else if t != whenBool {
+			return nil, "", p.errorf(operandToken, "operand of ! is %s, expected bool", t)
+		}
+		return &whenNot{operand}, whenBool, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whenParser) parseComparison() (whenNode, whenType, error) {
+	leftToken := p.peek()
+	left, leftType, err := p.parseOperand()
+	if err != nil {
+		return nil, "", err
+	}
+	opToken := p.peek()
+	if opToken.kind != whenOperator {
+		return left, leftType, nil
+	}
+	switch op := opToken.text; op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		rightToken := p.peek()
+		right, rightType, err := p.parseOperand()
+		if err != nil {
+			return nil, "", err
+		} else if rightType != leftType {
+			return nil, "", p.errorf(rightToken, "cannot compare %s with %s", leftType, rightType)
+		} else if op != "==" && op != "!=" && leftType != whenInt {
+			return nil, "", p.errorf(opToken, "%s needs int operands, got %s", op, leftType)
+		}
+		return &whenBinary{op: op, left: left, right: right}, whenBool, nil
+	case "=~", "!~":
+		p.next()
+		if leftType != whenString {
+			return nil, "", p.errorf(leftToken, "left operand of %s is %s, expected string", op, leftType)
+		}
+		rightToken := p.next()
+		if rightToken.kind != whenStringToken {
+			return nil, "", p.errorf(rightToken, "right operand of %s must be a string literal, got %s", op, rightToken)
+		}
+		regex, err := regexp.Compile(rightToken.value.(string))
+		if err != nil {
+			return nil, "", p.errorf(rightToken, "invalid regular expression: %v", err)
+		}
+		return &whenBinary{op: op, left: left, regex: regex}, whenBool, nil
+	case "in":
+		p.next()
+		list, err := p.parseList(leftType)
+		if err != nil {
+			return nil, "", err
+		}
+		return &whenBinary{op: op, left: left, list: list}, whenBool, nil
+	}
+	return left, leftType, nil
+}
+
+// parseList parses a list of literals, e.g. [403, 429], of the type of the left operand of in.
+func (p *whenParser) parseList(t whenType) (list []any, err error) {
+	if err = p.expect("["); err != nil {
+		return
+	}
+	for !p.isOperator("]") {
+		if len(list) > 0 {
+			if err = p.expect(","); err != nil {
+				return
+			}
+		}
+		token := p.next()
+		switch {
+		case token.kind == whenStringToken && t == whenString, token.kind == whenIntToken && t == whenInt:
+			list = append(list, token.value)
+		default:
+			return nil, p.errorf(token, "list items must be %s literals, got %s", t, token)
+		}
+	}
+	p.next()
+	return
+}
+
+func (p *whenParser) parseOperand() (whenNode, whenType, error) {
+	token := p.next()
+	switch token.kind {
+	case whenStringToken:
+		return &whenLiteral{token.value}, whenString, nil
+	case whenIntToken:
+		return &whenLiteral{token.value}, whenInt, nil
+	case whenIdent:
+		switch token.text {
+		case "true":
+			return &whenLiteral{true}, whenBool, nil
+		case "false":
+			return &whenLiteral{false}, whenBool, nil
+		}
+		if p.isOperator("(") {
+			return p.parseCall(token)
+		}
+		t, ok := whenVariables[token.text]
+		if !ok {
+			return nil, "", p.errorf(token, "unknown variable %s, expected one of: %s", token.text, strings.Join(getWhenVariableNames(), ", "))
+		}
+		return &whenVariable{token.text}, t, nil
+	case whenOperator:
+		if token.text == "(" {
+			node, t, err := p.parseOr()
+			if err != nil {
+				return nil, "", err
+			}
+			return node, t, p.expect(")")
+		}
+	}
+	return nil, "", p.errorf(token, "unexpected %s", token)
+}
+
+func (p *whenParser) parseCall(name whenToken) (whenNode, whenType, error) {
+	function, ok := whenFunctions[name.text]
+	if !ok {
+		return nil, "", p.errorf(name, "unknown function %s", name.text)
+	}
+	p.next()
+	call := &whenCall{function: function}
+	for !p.isOperator(")") {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, "", err
+			}
+		}
+		argToken := p.peek()
+		arg, t, err := p.parseOr()
+		if err != nil {
+			return nil, "", err
+		}
+		if i := len(call.args); i < len(function.params) && t != function.params[i] {
+			return nil, "", p.errorf(argToken, "argument %d of %s is %s, expected %s", i+1, name.text, t, function.params[i])
+		}
+		call.args = append(call.args, arg)
+	}
+	p.next()
+	if len(call.args) != len(function.params) {
+		return nil, "", p.errorf(name, "%s expects %d arguments, got %d", name.text, len(function.params), len(call.args))
+	}
+	return call, function.result, nil
+}
+
+func getWhenVariableNames() (names []string) {
+	for name := range whenVariables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return
+}
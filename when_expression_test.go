@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhenExpressionMatch(t *testing.T) {
+	linkedIn := UrlErrorLink{Url: "https://www.linkedin.com/company/my-org?trk=x", Error: "403"}
+	timeout := UrlErrorLink{Url: "https://partner.com/docs", Error: "timeout"}
+	internal := UrlErrorLink{Url: "https://my-site.com/missing", Error: "404"}
+	for _, tc := range []struct {
+		expr    string
+		page    string
+		link    UrlErrorLink
+		isMatch bool
+	}{
+		{`link.host =~ "(^|\.)linkedin\.com$" && status == 403 && !startsWith(page.path, "/careers")`, "https://my-site.com/about", linkedIn, true},
+		{`link.host =~ "(^|\.)linkedin\.com$" && status == 403 && !startsWith(page.path, "/careers")`, "https://my-site.com/careers/jobs", linkedIn, false},
+		{`category == "timeout" && external`, "https://my-site.com/", timeout, true},
+		{`category == "timeout" && external`, "https://my-site.com/", internal, false},
+		{`internal && status >= 400 && status < 500`, "https://my-site.com/", internal, true},
+		{`status in [403, 429] || link.query != ""`, "https://my-site.com/", linkedIn, true},
+		{`link.path in ['/docs', '/api'] && error !~ "^5"`, "https://my-site.com/", timeout, true},
+		{`(endsWith(lower(link), "/docs") || contains(page, "blog")) && true`, "https://my-site.com/", timeout, true},
+		{`page.query == "" && link.host == page.host`, "https://my-site.com/?lang=en", internal, false},
+	} {
+		expr, err := compileWhen(tc.expr)
+		assert.Nil(t, err, tc.expr)
+		assert.Equal(t, tc.isMatch, expr.isMatch(tc.page, tc.link), tc.expr)
+	}
+}
+
+func TestWhenExpressionErrors(t *testing.T) {
+	for expr, expected := range map[string]string{
+		`status`:                       "expression is int, expected bool",
+		`status == "403"`:              "position 11: cannot compare int with string",
+		`link < "b"`:                   "position 6: < needs int operands, got string",
+		`host == "a"`:                  "position 1: unknown variable host, expected one of: category, error, external, internal, link, link.host, link.path, link.query, page, page.host, page.path, page.query, status",
+		`status =~ "4.."`:              "position 1: left operand of =~ is int, expected string",
+		`link =~ page`:                 `position 9: right operand of =~ must be a string literal, got "page"`,
+		`link =~ "("`:                  "position 9: invalid regular expression: error parsing regexp: missing closing ): `(`",
+		`external && status`:           "position 13: right operand of && is int, expected bool",
+		`!link`:                        "position 2: operand of ! is string, expected bool",
+		`status in ["403"]`:            `position 12: list items must be int literals, got "\"403\""`,
+		`startsWith(link)`:             "position 1: startsWith expects 2 arguments, got 1",
+		`startsWith(status, "4")`:      "position 12: argument 1 of startsWith is int, expected string",
+		`size(link) > 3`:               "position 1: unknown function size",
+		`(external`:                    `position 10: expected ")", got end of expression`,
+		`external internal`:            `position 10: unexpected "internal"`,
+		`link == "a`:                   "position 9: unterminated string",
+		`link == 'a' ; true`:           "position 13: unexpected character ';'",
+		`external || status == 403 ==`: `position 27: unexpected "=="`,
+	} {
+		_, err := compileWhen(expr)
+		assert.EqualError(t, err, expected, expr)
+	}
+}
+
+func TestIgnoreRuleWhen(t *testing.T) {
+	rule := IgnoreRule{Url: "linkedin", Error: "403", When: `!startsWith(page.path, "/careers")`}
+	link := UrlErrorLink{Url: "https://www.linkedin.com/company/my-org", Error: "403"}
+	assert.True(t, rule.isMatch("https://my-site.com/about", link))
+	assert.False(t, rule.isMatch("https://my-site.com/careers/", link))
+
+	rule.When = "invalid ("
+	assert.False(t, rule.isMatch("https://my-site.com/about", link))
+}
+
+func TestLoadIgnoresInvalidWhen(t *testing.T) {
+	ignoreListFile := filepath.Join(t.TempDir(), "ignores.yaml")
+	assert.Nil(t, os.WriteFile(ignoreListFile, []byte(`
+- url: linkedin
+  when: status == "403"
+`), 0644))
+
+	_, err := loadIgnores(&arguments{IgnoresJson: []string{ignoreListFile}})
+	assert.EqualError(t, err, ignoreListFile+`:3:9: rule 0: invalid when expression: position 11: cannot compare int with string`)
+}